@@ -1,9 +1,10 @@
 package gottings
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 type Options map[string]interface{}
@@ -29,113 +30,207 @@ type UnmarshalableOption interface {
 //	     }
 //	     return &config, nil
 //	 }
-func LoadOptions(options Options, v any) error {
-	rv := reflect.ValueOf(v)
-	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
-		return errors.New("expected pointer to struct")
+//
+// Each field is set from the option named by its opt tag, then its flag
+// tag, then its Go name. The fields of a nested struct are set from dotted
+// keys made of the name of the struct field and the names of its own
+// fields:
+//
+//	type Config struct {
+//	     Port     int      `opt:"port"`
+//	     Database Database `opt:"db"`
+//	}
+//
+//	type Database struct {
+//	     Host string `opt:"host"` // set from "db.host"
+//	}
+//
+// The NormalizeKeys option makes keys match regardless of case, dashes and
+// underscores, so that "db.max-conns" and "DB.MAX_CONNS" both set the
+// field named "db.maxConns".
+//
+// Pointer fields are only allocated when their option is present, and
+// options may themselves be pointers, such as those returned by flag.Int.
+// The value they point to is copied unless the AliasPointers option is
+// given.
+//
+// Fields without an option that still hold their zero value are filled
+// from their default tag.
+func LoadOptions(options Options, v any, opts ...LoadOption) error {
+	elem, err := structElem(v)
+	if err != nil {
+		return err
 	}
-	elem := rv.Elem()
+	st := newLoadState(opts)
+	st.loadOptions(elem, options)
+	if !st.layered {
+		st.applyDefaults(elem, "")
+	}
+	return st.err()
+}
+
+// loadOptions sets the fields of elem named by the keys of options.
+func (st *loadState) loadOptions(elem reflect.Value, options Options) {
+	keys := optionKeys{options: options}
+	if st.normalizeKeys {
+		keys.normalized = map[string]string{}
+		names := make([]string, 0, len(options))
+		for name := range options {
+			names = append(names, name)
+		}
+		// Sorted so that the same key wins when several normalize alike.
+		sort.Strings(names)
+		for _, name := range names {
+			normalized := normalizeKey(name)
+			if _, ok := keys.normalized[normalized]; !ok {
+				keys.normalized[normalized] = name
+			}
+		}
+	}
+	st.loadOptionFields(elem, keys, "", "")
+}
+
+// loadOptionFields sets the fields of elem from keys, prefixing their
+// option names with prefix. path is the field path of elem. It reports
+// whether any field was set.
+func (st *loadState) loadOptionFields(elem reflect.Value, keys optionKeys, prefix, path string) bool {
+	defer st.stack.push(elem)()
+	set := false
 	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
 		fieldValue := elem.Field(i)
-		var targetValue reflect.Value
-		if fieldValue.Kind() == reflect.Pointer {
-			if fieldValue.IsNil() {
-				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		name := optionName(field)
+		if name == "-" {
+			continue
+		}
+		fieldPath := joinPath(path, field.Name)
+		key, value, ok := keys.lookup(prefix + name)
+		if !field.IsExported() {
+			if ok {
+				st.addError(&FieldError{Path: fieldPath, Source: st.optionSource, Key: key, Err: errUnexported})
 			}
-			targetValue = fieldValue.Elem()
-		} else {
-			targetValue = fieldValue
+			continue
+		}
+		if !ok && isNestedStruct(field.Type) {
+			set = st.stack.descend(fieldValue, func(nested reflect.Value) bool {
+				return st.loadOptionFields(nested, keys, prefix+name+".", fieldPath)
+			}) || set
+			continue
 		}
-		fieldName := elem.Type().Field(i).Name
-		value, ok := options[fieldName]
 		if !ok {
 			continue
 		}
-		switch targetValue.Kind() {
-		case reflect.Bool:
-			var v bool
-			switch n := value.(type) {
-			case bool:
-				v = bool(n)
-			case *bool:
-				v = bool(*n)
-			default:
-				return fmt.Errorf("failed to set bool field %s due to type mismatch", fieldName)
-			}
-			targetValue.SetBool(v)
-		case reflect.Int:
-			v, err := ToInt64(value)
-			if err != nil {
-				return fmt.Errorf("failed to set field %s due to type mismatch err %s", fieldName, err)
-			}
-			targetValue.SetInt(v)
+		if err := setOptionField(fieldValue, value, st.aliasPointers); err != nil {
+			st.addError(&FieldError{Path: fieldPath, Source: st.optionSource, Key: key, Err: err})
+			continue
+		}
+		st.record(fieldValue, field, Origin{Path: fieldPath, Source: st.optionSource, Key: key})
+		set = true
+	}
+	return set
+}
 
-		case reflect.Int8:
-			v, err := ToInt64(value)
-			if err != nil {
-				return fmt.Errorf("failed to set field %s due to type mismatch err %s", fieldName, err)
-			}
-			targetValue.SetInt(int64(v))
+// optionName returns the option name of field: its opt tag, then its flag
+// tag, then its Go name.
+func optionName(field reflect.StructField) string {
+	for _, tag := range []string{"opt", "flag"} {
+		if name, _ := parseTag(field.Tag.Get(tag)); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
 
-		case reflect.Int16:
-			v, err := ToInt64(value)
-			if err != nil {
-				return fmt.Errorf("failed to set field %s due to type mismatch err %s", fieldName, err)
-			}
-			targetValue.SetInt(int64(v))
+// optionKeys looks up the options of a LoadOptions call. normalized maps
+// normalized keys to the key of options when NormalizeKeys is given.
+type optionKeys struct {
+	options    Options
+	normalized map[string]string
+}
 
-		case reflect.Int32:
-			v, err := ToInt64(value)
-			if err != nil {
-				return fmt.Errorf("failed to set field %s due to type mismatch err %s", fieldName, err)
-			}
-			targetValue.SetInt(v)
+// lookup returns the key of options matching name and its value.
+func (k optionKeys) lookup(name string) (string, any, bool) {
+	if value, ok := k.options[name]; ok {
+		return name, value, true
+	}
+	if k.normalized == nil {
+		return "", nil, false
+	}
+	key, ok := k.normalized[normalizeKey(name)]
+	if !ok {
+		return "", nil, false
+	}
+	return key, k.options[key], true
+}
 
-		case reflect.Int64:
-			v, err := ToInt64(value)
-			if err != nil {
-				return fmt.Errorf("failed to set field %s due to type mismatch err %s", fieldName, err)
-			}
-			targetValue.SetInt(v)
+// normalizeKey lowers key and drops its dashes and underscores, so that
+// kebab-case, snake_case and camelCase spellings compare equal.
+func normalizeKey(key string) string {
+	return keySeparatorRemover.Replace(strings.ToLower(key))
+}
 
-		case reflect.String:
-			var v string
-			switch n := value.(type) {
-			case string:
-				v = string(n)
-			case *string:
-				v = string(*n)
-			default:
-				return fmt.Errorf("failed to set string field %s due to type mismatch", fieldName)
-			}
-			targetValue.SetString(v)
-		case reflect.Float32:
-			v, err := ToFloat64(value)
-			if err != nil {
-				return fmt.Errorf("failed to set field %s due to type mismatch err %s", fieldName, err)
-			}
-			targetValue.SetFloat(float64(v))
+var keySeparatorRemover = strings.NewReplacer("-", "", "_", "")
 
-		case reflect.Float64:
-			v, err := ToFloat64(value)
-			if err != nil {
-				return fmt.Errorf("failed to set field %s due to type mismatch err %s", fieldName, err)
-			}
-			targetValue.SetFloat(v)
-		case reflect.Struct:
-			unMarshalInterface := reflect.TypeOf((*UnmarshalableOption)(nil)).Elem()
-			if fieldValue.Addr().Type().Implements(unMarshalInterface) {
-				unmarshaler := fieldValue.Addr().Interface().(UnmarshalableOption)
-				if err := unmarshaler.UnmarshalOption(value); err != nil {
-					return err
-				}
-			}
-		default:
-			return fmt.Errorf("unexpected field type: %s", elem.Type().Field(i).Type.Kind())
-		}
+// setOptionField stores value into fieldValue. A pointer field is set
+// through the value it points to, and a nil pointer is only allocated once
+// value was converted, so that nil keeps meaning "not configured". With
+// alias, a pointer of the type of the field is stored as is instead.
+func setOptionField(fieldValue reflect.Value, value any, alias bool) error {
+	if fieldValue.Kind() != reflect.Pointer {
+		return setOption(fieldValue, value)
+	}
+	if alias && value != nil && reflect.TypeOf(value) == fieldValue.Type() {
+		fieldValue.Set(reflect.ValueOf(value))
+		return nil
+	}
+	if !fieldValue.IsNil() {
+		return setOption(fieldValue.Elem(), value)
+	}
+	target := reflect.New(fieldValue.Type().Elem())
+	if err := setOption(target.Elem(), value); err != nil {
+		return err
 	}
+	fieldValue.Set(target)
 	return nil
 }
 
-// I.e. if the flag has the type not specified --> Can still set to int, int8, ... depending on the field.
-// TODO: Pointer support
+// setOption stores value into targetValue, which must not be a pointer.
+// A value of the type of the field is stored as is. Types implementing
+// UnmarshalableOption receive any other value as is; any other
+// value must either be assignable to the field, be a pointer to such a
+// value, be a string parsed by a time.Duration, complex or text
+// unmarshaling field, or be a number, or a pointer to one, that setNumber
+// converts without loss.
+func setOption(targetValue reflect.Value, value any) error {
+	if value != nil && reflect.TypeOf(value) == targetValue.Type() {
+		targetValue.Set(reflect.ValueOf(value))
+		return nil
+	}
+	if unmarshaler, ok := targetValue.Addr().Interface().(UnmarshalableOption); ok {
+		return unmarshaler.UnmarshalOption(value)
+	}
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() {
+		return fmt.Errorf("cannot use nil as %s", targetValue.Type())
+	}
+	if rv.Kind() == reflect.Pointer && rv.Type().Elem().AssignableTo(targetValue.Type()) {
+		if rv.IsNil() {
+			return fmt.Errorf("cannot use nil %s as %s", rv.Type(), targetValue.Type())
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type().AssignableTo(targetValue.Type()) {
+		targetValue.Set(rv)
+		return nil
+	}
+	if s, ok := value.(string); ok && (targetValue.Type() == durationType || hasUnmarshaler(targetValue.Type()) || isComplexKind(targetValue.Kind())) {
+		return setScalar(targetValue, s)
+	}
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if isNumberKind(targetValue.Kind()) && isNumberKind(rv.Kind()) {
+		return setNumber(targetValue, rv)
+	}
+	return fmt.Errorf("cannot use %T as %s", value, targetValue.Type())
+}
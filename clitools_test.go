@@ -1,8 +1,11 @@
 package gottings
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -123,7 +126,7 @@ func TestLoadOptions(t *testing.T) {
 		if err != nil {
 			t.Fatalf("error not expected received: %s", err)
 		}
-		if reflect.DeepEqual(config, expected) {
+		if !reflect.DeepEqual(config, expected) {
 			t.Fatalf("result %v and expected %v not equal", config, expected)
 		}
 	},
@@ -152,7 +155,7 @@ func TestLoadOptions(t *testing.T) {
 		if err != nil {
 			t.Fatalf("error not expected received: %s", err)
 		}
-		if reflect.DeepEqual(config, expected) {
+		if !reflect.DeepEqual(config, expected) {
 			t.Fatalf("result %v and expected %v not equal", config, expected)
 		}
 	})
@@ -177,16 +180,315 @@ func TestLoadOptions(t *testing.T) {
 			"NullBool":    &Bool,
 			"String":      &String,
 			"NullString":  &String,
-		}, &config)
+		}, &config2)
 		if err != nil {
 			t.Fatalf("error not expected received: %s", err)
 		}
-		if reflect.DeepEqual(config2, expected2) {
+		if !reflect.DeepEqual(config2, expected2) {
 			t.Fatalf("result %v and expected %v not equal", config2, expected2)
 		}
 	})
 }
 
+func TestLoadOptionsErrors(t *testing.T) {
+	type Unsupported struct {
+		Value int
+	}
+	type Config struct {
+		Int         int
+		NullInt     NullInt
+		NullString  NullString
+		Unsupported Unsupported
+	}
+	testCases := []struct {
+		name    string
+		options Options
+	}{
+		{name: "string into int", options: Options{"Int": "1"}},
+		{name: "string into NullInt", options: Options{"NullInt": "1"}},
+		{name: "int into NullString", options: Options{"NullString": 1}},
+		{name: "unsupported struct", options: Options{"Unsupported": 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := LoadOptions(tc.options, &Config{}); err == nil {
+				t.Fatalf("expected error, got %v", err)
+			}
+		})
+	}
+	t.Run("overflow is a field error", func(t *testing.T) {
+		type Config struct {
+			Small int8
+		}
+		config := Config{Small: 1}
+		err := LoadOptions(Options{"Small": int64(300)}, &config)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Path != "Small" || !errors.Is(err, strconv.ErrRange) {
+			t.Fatalf("expected *FieldError wrapping strconv.ErrRange, got %v", err)
+		}
+		if config.Small != 1 {
+			t.Errorf("expected Small to be unchanged, got %d", config.Small)
+		}
+	})
+	t.Run("nil marks null types unset", func(t *testing.T) {
+		config := Config{NullInt: NewNullInt(1)}
+		if err := LoadOptions(Options{"NullInt": nil}, &config); err != nil {
+			t.Fatalf("error not expected received: %s", err)
+		}
+		if config.NullInt.Valid {
+			t.Fatalf("expected NullInt to be unset, got %v", config.NullInt)
+		}
+	})
+	t.Run("assignable struct", func(t *testing.T) {
+		config := Config{}
+		if err := LoadOptions(Options{"Unsupported": Unsupported{Value: 1}}, &config); err != nil {
+			t.Fatalf("error not expected received: %s", err)
+		}
+		if config.Unsupported.Value != 1 {
+			t.Fatalf("expected Unsupported.Value=1, got %v", config.Unsupported)
+		}
+	})
+}
+
+func TestLoadOptionsUnsigned(t *testing.T) {
+	type Config struct {
+		Uint       uint
+		NullUint   NullUint
+		Uint8      uint8
+		NullUint8  NullUint8
+		Uint16     *uint16
+		NullUint32 NullUint32
+		Uint64     uint64
+		NullUint64 NullUint64
+		Complex64  complex64
+		Complex128 complex128
+	}
+	uint16Value := uint16(8080)
+	options := Options{
+		"Uint":       uint(1),
+		"NullUint":   2,
+		"Uint8":      int64(255),
+		"NullUint8":  uint8(8),
+		"Uint16":     &uint16Value,
+		"NullUint32": 32.0,
+		"Uint64":     uint64(math.MaxUint64),
+		"NullUint64": NewNullUint64(64),
+		"Complex64":  complex64(complex(1, 2)),
+		"Complex128": "3+4i",
+	}
+	config := Config{}
+	if err := LoadOptions(options, &config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := Config{
+		Uint:       1,
+		NullUint:   NewNullUint(2),
+		Uint8:      255,
+		NullUint8:  NewNullUint8(8),
+		Uint16:     &uint16Value,
+		NullUint32: NewNullUint32(32),
+		Uint64:     math.MaxUint64,
+		NullUint64: NewNullUint64(64),
+		Complex64:  complex(1, 2),
+		Complex128: complex(3, 4),
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("result %+v does not match expected %+v", config, expected)
+	}
+	if config.Uint16 == &uint16Value {
+		t.Errorf("expected Uint16 to be copied")
+	}
+
+	testCases := []struct {
+		name     string
+		options  Options
+		errRange bool
+	}{
+		{name: "negative into uint", options: Options{"Uint": -1}, errRange: true},
+		{name: "overflow into NullUint8", options: Options{"NullUint8": 256}, errRange: true},
+		{name: "fraction into uint64", options: Options{"Uint64": 1.5}},
+		{name: "imaginary part into uint", options: Options{"Uint": complex(1, 1)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := LoadOptions(tc.options, &Config{})
+			if err == nil {
+				t.Fatalf("expected error, got %v", err)
+			}
+			if tc.errRange && !errors.Is(err, strconv.ErrRange) {
+				t.Fatalf("expected strconv.ErrRange, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadOptionsSelfReferential(t *testing.T) {
+	type Node struct {
+		Name string `opt:"name"`
+		Next *Node  `opt:"next"`
+	}
+	node := Node{}
+	if err := LoadOptions(Options{"name": "x", "next.name": "y"}, &node); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if node.Name != "x" || node.Next != nil {
+		t.Errorf("unexpected result %+v", node)
+	}
+}
+
+func TestLoadOptionsKeys(t *testing.T) {
+	type Pool struct {
+		MaxConns int `opt:"maxConns"`
+	}
+	type Database struct {
+		Host string `opt:"host"`
+		Pool Pool   `flag:"pool"`
+	}
+	type Config struct {
+		Port     int  `opt:"port" flag:"listen"`
+		Verbose  bool `flag:"verbose"`
+		Name     string
+		Skipped  string    `opt:"-"`
+		Database *Database `opt:"db"`
+		hidden   string
+	}
+
+	t.Run("tags and dotted keys", func(t *testing.T) {
+		var report Report
+		config := Config{}
+		options := Options{
+			"port":              8080,
+			"verbose":           true,
+			"Name":              "app",
+			"Skipped":           "skipped",
+			"-":                 "skipped",
+			"db.host":           "db",
+			"db.pool.maxConns":  10,
+			"listen":            1,
+			"Database.Host":     "ignored",
+			"db.pool.max-conns": 20,
+		}
+		if err := LoadOptions(options, &config, WithReport(&report)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Config{
+			Port:     8080,
+			Verbose:  true,
+			Name:     "app",
+			Database: &Database{Host: "db", Pool: Pool{MaxConns: 10}},
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+		origin, _ := report.Lookup("Database.Pool.MaxConns")
+		if origin.Key != "db.pool.maxConns" {
+			t.Errorf("expected key db.pool.maxConns, got %+v", origin)
+		}
+	})
+	t.Run("normalized keys", func(t *testing.T) {
+		testCases := []struct {
+			name string
+			key  string
+		}{
+			{name: "exact", key: "db.pool.maxConns"},
+			{name: "kebab case", key: "db.pool.max-conns"},
+			{name: "snake case", key: "db.pool.max_conns"},
+			{name: "upper case", key: "DB.POOL.MAX_CONNS"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				config := Config{}
+				if err := LoadOptions(Options{tc.key: 10}, &config, NormalizeKeys()); err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if config.Database == nil || config.Database.Pool.MaxConns != 10 {
+					t.Errorf("expected MaxConns=10 from %s, got %+v", tc.key, config.Database)
+				}
+			})
+		}
+
+		config := Config{}
+		if err := LoadOptions(Options{"PORT": 1, "db.pool.max-conns": 10}, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port != 0 || config.Database != nil && config.Database.Pool.MaxConns != 0 {
+			t.Errorf("expected keys to match exactly without NormalizeKeys, got %+v", config)
+		}
+	})
+	t.Run("unexported field", func(t *testing.T) {
+		err := LoadOptions(Options{"hidden": "x"}, &Config{})
+		if !errors.Is(err, errUnexported) {
+			t.Errorf("expected errUnexported, got %v", err)
+		}
+	})
+}
+
+func TestLoadOptionsPointers(t *testing.T) {
+	type Database struct {
+		Host string
+	}
+	type Config struct {
+		Port     *int
+		Host     *string
+		Ratio    *float64
+		Debug    *NullBool
+		Database *Database
+	}
+
+	t.Run("absent options leave nil pointers", func(t *testing.T) {
+		config := Config{}
+		if err := LoadOptions(Options{"Port": 8080}, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port == nil || *config.Port != 8080 {
+			t.Fatalf("expected Port=8080, got %v", config.Port)
+		}
+		if config.Host != nil || config.Ratio != nil || config.Debug != nil || config.Database != nil {
+			t.Errorf("expected nil pointers, got %+v", config)
+		}
+	})
+	t.Run("failed conversion leaves nil pointers", func(t *testing.T) {
+		config := Config{}
+		if err := LoadOptions(Options{"Port": "http"}, &config); err == nil {
+			t.Fatalf("expected error, got %v", err)
+		}
+		if config.Port != nil {
+			t.Errorf("expected nil Port, got %v", *config.Port)
+		}
+	})
+	t.Run("pointer options are copied", func(t *testing.T) {
+		port := 8080
+		existing := 1
+		config := Config{Host: new(string), Port: &existing}
+		if err := LoadOptions(Options{"Port": &port, "Host": "example.com"}, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port != &existing || existing != 8080 {
+			t.Errorf("expected the existing pointer to be updated, got %v", config.Port)
+		}
+		if *config.Host != "example.com" {
+			t.Errorf("Expected example.com got %s", *config.Host)
+		}
+	})
+	t.Run("pointer options are aliased", func(t *testing.T) {
+		port := 8080
+		config := Config{}
+		if err := LoadOptions(Options{"Port": &port, "Ratio": 0.5}, &config, AliasPointers()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port != &port {
+			t.Errorf("expected Port to alias the option")
+		}
+		if config.Ratio == nil || *config.Ratio != 0.5 {
+			t.Errorf("expected Ratio=0.5, got %v", config.Ratio)
+		}
+		port = 9090
+		if *config.Port != 9090 {
+			t.Errorf("expected the field to follow the option, got %d", *config.Port)
+		}
+	})
+}
+
 func TestType(t *testing.T) {
 	var a int = 2
 	fmt.Printf("%T\n", a)
@@ -0,0 +1,40 @@
+package gottings
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// applyDefaults fills every field of elem that carries a default tag, was
+// not set by the current call and still holds its zero value. The default
+// is converted exactly like an environment value. It reports whether any
+// field was set.
+func (st *loadState) applyDefaults(elem reflect.Value, path string) bool {
+	defer st.stack.push(elem)()
+	set := false
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := elem.Field(i)
+		fieldPath := joinPath(path, field.Name)
+		if isNestedStruct(field.Type) {
+			set = st.stack.descend(fieldValue, func(nested reflect.Value) bool {
+				return st.applyDefaults(nested, fieldPath)
+			}) || set
+			continue
+		}
+		def, ok := field.Tag.Lookup("default")
+		if !ok || st.set[fieldPath] || !fieldValue.IsZero() {
+			continue
+		}
+		if err := setEnvValue(fieldValue, def, field.Tag); err != nil {
+			st.addError(&FieldError{Path: fieldPath, Source: SourceDefault, Err: fmt.Errorf("invalid default %q: %w", def, err)})
+			continue
+		}
+		st.record(fieldValue, field, Origin{Path: fieldPath, Source: SourceDefault})
+		set = true
+	}
+	return set
+}
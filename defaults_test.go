@@ -0,0 +1,101 @@
+package gottings
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaults(t *testing.T) {
+	type Database struct {
+		Host string `env:"HOST" json:"host" default:"localhost"`
+	}
+	type Config struct {
+		Port     int                 `env:"TEST_PORT" json:"port" default:"8080"`
+		NullPort NullInt             `env:"TEST_NULLPORT" json:"null_port" default:"8081"`
+		Debug    *bool               `env:"TEST_DEBUG" json:"debug" default:"true"`
+		Tags     []string            `env:"TEST_TAGS" json:"tags" default:"a,b"`
+		Timeout  Null[time.Duration] `env:"TEST_TIMEOUT" json:"timeout" default:"5s"`
+		Name     string              `env:"TEST_NAME" json:"name"`
+		Database *Database           `json:"database" envPrefix:"TEST_DB_"`
+	}
+	debug := true
+	defaults := Config{
+		Port:     8080,
+		NullPort: NewNullInt(8081),
+		Debug:    &debug,
+		Tags:     []string{"a", "b"},
+		Timeout:  NewNull(5 * time.Second),
+		Database: &Database{Host: "localhost"},
+	}
+
+	t.Run("LoadEnv", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "9090")
+		config := Config{}
+		if err := LoadEnv(&config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := defaults
+		expected.Port = 9090
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("LoadEnv - explicit zero value is kept", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "0")
+		config := Config{}
+		if err := LoadEnv(&config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port != 0 {
+			t.Errorf("Expected 0 got config.Port=%d\n", config.Port)
+		}
+	})
+	t.Run("LoadEnv - fields already set are kept", func(t *testing.T) {
+		config := Config{Port: 1312}
+		if err := LoadEnv(&config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port != 1312 {
+			t.Errorf("Expected 1312 got config.Port=%d\n", config.Port)
+		}
+	})
+	t.Run("LoadConfiguration", func(t *testing.T) {
+		t.Setenv("TEST_NAME", "app")
+		config := Config{}
+		err := LoadConfiguration([]byte(`{"port": 9090, "debug": false, "database": {"host": "db"}}`), &config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		debug := false
+		expected := defaults
+		expected.Port = 9090
+		expected.Debug = &debug
+		expected.Name = "app"
+		expected.Database = &Database{Host: "db"}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("LoadOptions", func(t *testing.T) {
+		config := Config{}
+		if err := LoadOptions(Options{"Port": 9090}, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := defaults
+		expected.Port = 9090
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("malformed default", func(t *testing.T) {
+		type Config struct {
+			Port NullInt `default:"http"`
+		}
+		err := LoadEnv(&Config{})
+		if err == nil || !strings.Contains(err.Error(), "Port") {
+			t.Fatalf("expected error naming the field, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,349 @@
+package gottings
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ParseDotEnv parses a .env file:
+//
+//	# comments and blank lines are ignored
+//	export HOST=127.0.0.1       # the export prefix is optional
+//	NAME='single quoted, taken literally'
+//	GREETING="double quoted\nwith escapes"
+//	CERT="multi-line values
+//	span several lines when quoted"
+//	URL=http://${HOST}:${PORT:-8080}
+//
+// ${VAR} and $VAR are expanded in unquoted and double-quoted values, first
+// from the variables defined earlier in the file and then from the process
+// environment. ${VAR:-default} expands to default when VAR is unset or
+// empty. A backslash escapes a dollar sign in double-quoted values.
+//
+// ParseDotEnv never modifies the process environment.
+func ParseDotEnv(r io.Reader) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &dotEnvParser{src: string(data), line: 1, vars: map[string]string{}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.vars, nil
+}
+
+// LoadDotEnv parses the .env file at path and populates v from its
+// variables as LoadEnv populates it from the environment. Variables missing
+// from the file are not read from the process environment.
+//
+// The process environment is left untouched unless the Setenv option is
+// given.
+func LoadDotEnv(path string, v any, opts ...LoadOption) error {
+	elem, err := structElem(v)
+	if err != nil {
+		return err
+	}
+	st := newLoadState(opts)
+	vars, err := readDotEnv(path)
+	if err != nil {
+		return err
+	}
+	if err := st.exportDotEnv(vars); err != nil {
+		return err
+	}
+	st.lookup = MapLookup(vars)
+	st.envSource = SourceDotEnv
+	st.file = path
+	return st.loadEnvironment(elem)
+}
+
+// loadDotEnvDocument populates elem from the variables of the .env document
+// data, for LoadFile. The lookup of st is left untouched so that the
+// process environment still overrides the file afterwards.
+func (st *loadState) loadDotEnvDocument(elem reflect.Value, data []byte) error {
+	vars, err := ParseDotEnv(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if err := st.exportDotEnv(vars); err != nil {
+		return err
+	}
+	lookup, source := st.lookup, st.envSource
+	st.lookup, st.envSource = MapLookup(vars), SourceDotEnv
+	st.loadEnv(elem, "", "")
+	st.lookup, st.envSource = lookup, source
+	return nil
+}
+
+// exportDotEnv exports vars to the process environment when the Setenv
+// option is given, without overwriting variables that are already set.
+func (st *loadState) exportDotEnv(vars map[string]string) error {
+	if !st.setenv {
+		return nil
+	}
+	for key, value := range vars {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Setenv makes LoadDotEnv export the variables of the file to the process
+// environment. Variables that are already set are not overwritten.
+func Setenv() LoadOption {
+	return func(st *loadState) {
+		st.setenv = true
+	}
+}
+
+func readDotEnv(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	vars, err := ParseDotEnv(f)
+	if syntaxErr, ok := err.(*SyntaxError); ok {
+		syntaxErr.File = path
+	}
+	return vars, err
+}
+
+type dotEnvParser struct {
+	src  string
+	pos  int
+	line int
+	vars map[string]string
+}
+
+func (p *dotEnvParser) parse() error {
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		if strings.HasPrefix(p.src[p.pos:], "export ") || strings.HasPrefix(p.src[p.pos:], "export\t") {
+			p.pos += len("export")
+			p.skipSpaces()
+		}
+		key := p.readKey()
+		if key == "" {
+			return p.errorf("expected variable name")
+		}
+		p.skipSpaces()
+		if p.eof() || p.peek() != '=' {
+			return p.errorf("expected '=' after %s", key)
+		}
+		p.pos++
+		p.skipSpaces()
+		value, err := p.readValue()
+		if err != nil {
+			return err
+		}
+		p.vars[key] = value
+	}
+}
+
+func (p *dotEnvParser) readKey() string {
+	start := p.pos
+	for !p.eof() && isDotEnvKeyByte(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func isDotEnvKeyByte(c byte, first bool) bool {
+	switch {
+	case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		return true
+	case '0' <= c && c <= '9' || c == '.':
+		return !first
+	}
+	return false
+}
+
+func (p *dotEnvParser) readValue() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+	switch p.peek() {
+	case '\'':
+		return p.readQuoted('\'')
+	case '"':
+		return p.readQuoted('"')
+	}
+	var b strings.Builder
+	for !p.eof() && p.peek() != '\n' {
+		c := p.peek()
+		if c == '#' && (b.Len() == 0 || isSpace(b.String()[b.Len()-1])) {
+			p.skipLine()
+			break
+		}
+		if c == '$' {
+			if err := p.expand(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return strings.TrimRight(b.String(), " \t\r"), nil
+}
+
+func (p *dotEnvParser) readQuoted(quote byte) (string, error) {
+	startLine := p.line
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() {
+			p.line = startLine
+			return "", p.errorf("unterminated %c quoted value", quote)
+		}
+		c := p.peek()
+		switch {
+		case c == quote:
+			p.pos++
+			p.skipSpaces()
+			if !p.eof() && p.peek() == '#' {
+				p.skipLine()
+			} else if !p.eof() && p.peek() != '\n' && p.peek() != '\r' {
+				return "", p.errorf("unexpected character %q after quoted value", p.peek())
+			}
+			return b.String(), nil
+		case c == '\\' && quote == '"' && p.pos+1 < len(p.src):
+			p.pos++
+			if p.peek() == '\n' {
+				p.line++
+			}
+			b.WriteString(unescapeDotEnv(p.peek()))
+			p.pos++
+		case c == '$' && quote == '"':
+			if err := p.expand(&b); err != nil {
+				return "", err
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func unescapeDotEnv(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '"', '\\', '$':
+		return string(c)
+	}
+	return "\\" + string(c)
+}
+
+// expand writes the value of the variable referenced at the current $ to b.
+func (p *dotEnvParser) expand(b *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		b.WriteByte('$')
+		return nil
+	}
+	if p.peek() != '{' {
+		name := p.readVarName()
+		if name == "" {
+			b.WriteByte('$')
+			return nil
+		}
+		b.WriteString(p.lookup(name))
+		return nil
+	}
+	end := strings.IndexByte(p.src[p.pos:], '}')
+	if end < 0 {
+		return p.errorf("unterminated variable reference")
+	}
+	ref := p.src[p.pos+1 : p.pos+end]
+	p.pos += end + 1
+	name, fallback, hasFallback := strings.Cut(ref, ":-")
+	value := p.lookup(name)
+	if value == "" && hasFallback {
+		value = fallback
+	}
+	b.WriteString(value)
+	return nil
+}
+
+// readVarName reads the name of a $VAR reference. Unlike keys, names stop at
+// a dot, as in shells, so that $HOST.internal expands HOST.
+func (p *dotEnvParser) readVarName() string {
+	start := p.pos
+	for !p.eof() && isDotEnvKeyByte(p.peek(), p.pos == start) && p.peek() != '.' {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *dotEnvParser) lookup(name string) string {
+	if value, ok := p.vars[name]; ok {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+func (p *dotEnvParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case '\n':
+			p.line++
+		case ' ', '\t', '\r':
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *dotEnvParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotEnvParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotEnvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotEnvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *dotEnvParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,153 @@
+package gottings
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	t.Setenv("TEST_DOTENV_HOME", "/home/app")
+	testCases := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{
+			name:     "comments and blank lines",
+			input:    "# comment\n\nKEY=value\n  # indented comment\n",
+			expected: map[string]string{"KEY": "value"},
+		},
+		{
+			name:     "export prefix",
+			input:    "export KEY=value\nexport\tOTHER=1",
+			expected: map[string]string{"KEY": "value", "OTHER": "1"},
+		},
+		{
+			name:     "unquoted values",
+			input:    "A = spaced value  \nB=value # comment\nC=a#b\nD=\n",
+			expected: map[string]string{"A": "spaced value", "B": "value", "C": "a#b", "D": ""},
+		},
+		{
+			name:     "single quotes",
+			input:    `KEY='${NOT} \n expanded' # comment`,
+			expected: map[string]string{"KEY": `${NOT} \n expanded`},
+		},
+		{
+			name:     "double quotes and escapes",
+			input:    `KEY="tab\there \"quoted\" \\ \$HOME"`,
+			expected: map[string]string{"KEY": "tab\there \"quoted\" \\ $HOME"},
+		},
+		{
+			name:     "multi-line values",
+			input:    "KEY=\"first\nsecond\"\nSINGLE='a\nb'\nNEXT=1",
+			expected: map[string]string{"KEY": "first\nsecond", "SINGLE": "a\nb", "NEXT": "1"},
+		},
+		{
+			name:     "expansion",
+			input:    "HOST=db\nURL=postgres://${HOST}:${PORT:-5432}/$HOST\nHOME_DIR=\"${TEST_DOTENV_HOME}/data\"\nEMPTY=${UNDEFINED_DOTENV_VAR}",
+			expected: map[string]string{"HOST": "db", "URL": "postgres://db:5432/db", "HOME_DIR": "/home/app/data", "EMPTY": ""},
+		},
+		{
+			name:     "expansion stops at a dot",
+			input:    "HOST=db\nHOST_PORT=5432\nURL=$HOST.internal\nQUOTED=\"$HOST.internal:$HOST_PORT\"",
+			expected: map[string]string{"HOST": "db", "HOST_PORT": "5432", "URL": "db.internal", "QUOTED": "db.internal:5432"},
+		},
+		{
+			name:     "windows line endings",
+			input:    "A=1\r\nB=\"2\"\r\n",
+			expected: map[string]string{"A": "1", "B": "2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vars, err := ParseDotEnv(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(vars, tc.expected) {
+				t.Errorf("result %q does not match expected %q", vars, tc.expected)
+			}
+		})
+	}
+
+	errorCases := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{name: "missing separator", input: "A=1\nB", line: 2},
+		{name: "invalid name", input: "A=1\n\n1A=2", line: 3},
+		{name: "unterminated quote", input: "A=1\nB=\"open\n\nC=3", line: 2},
+		{name: "garbage after quote", input: "A='x' y", line: 1},
+		{name: "unterminated reference", input: "A=${B", line: 1},
+		{name: "escaped newline in quotes", input: "A=\"x\\\ny\"\nB=1\nC", line: 4},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseDotEnv(strings.NewReader(tc.input))
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected *SyntaxError, got %v", err)
+			}
+			if syntaxErr.Line != tc.line {
+				t.Errorf("expected error on line %d, got %v", tc.line, syntaxErr)
+			}
+		})
+	}
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	type Config struct {
+		Host string   `env:"APP_HOST"`
+		Port int      `env:"APP_PORT"`
+		URL  string   `env:"APP_URL"`
+		Tags []string `env:"APP_TAGS"`
+	}
+	expected := Config{Host: "127.0.0.1", Port: 8080, URL: "http://127.0.0.1:8080", Tags: []string{"a", "b"}}
+
+	t.Run("process environment untouched", func(t *testing.T) {
+		config := Config{}
+		if err := LoadDotEnv("testdata/app.env", &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v", config, expected)
+		}
+		if _, ok := os.LookupEnv("APP_URL"); ok {
+			t.Errorf("expected APP_URL to stay unset in the process environment")
+		}
+	})
+	t.Run("Setenv", func(t *testing.T) {
+		t.Setenv("APP_HOST", "example.com")
+		t.Setenv("APP_URL", "")
+		os.Unsetenv("APP_URL")
+		config := Config{}
+		if err := LoadDotEnv("testdata/app.env", &config, Setenv()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if os.Getenv("APP_URL") != expected.URL {
+			t.Errorf("expected APP_URL=%s in the process environment, got %s", expected.URL, os.Getenv("APP_URL"))
+		}
+		if os.Getenv("APP_HOST") != "example.com" {
+			t.Errorf("expected APP_HOST to keep its value, got %s", os.Getenv("APP_HOST"))
+		}
+	})
+	t.Run("field errors name the dotenv source", func(t *testing.T) {
+		type Config struct {
+			Host int `env:"APP_HOST"`
+		}
+		err := LoadDotEnv("testdata/app.env", &Config{})
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Source != SourceDotEnv {
+			t.Fatalf("expected *FieldError from dotenv, got %v", err)
+		}
+	})
+	t.Run("missing file", func(t *testing.T) {
+		if err := LoadDotEnv("testdata/missing.env", &Config{}); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected os.ErrNotExist, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package gottings
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+const (
+	// redacted replaces the value of secret fields in dumps.
+	redacted = "******"
+	// unset is shown for Null fields that are not Valid and nil pointers.
+	unset = "<unset>"
+)
+
+// FieldDescription describes the effective value of a field, as listed by
+// Describe.
+type FieldDescription struct {
+	Path string `json:"path"`
+	// Value is the value of the field as text, redacted for secrets and
+	// "<unset>" for Null fields that are not Valid and nil pointers.
+	Value string `json:"value"`
+	// Source, Key, File and Line are taken from the Report given with
+	// DumpSources, when it knows the field.
+	Source Source `json:"source,omitempty"`
+	Key    string `json:"key,omitempty"`
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+}
+
+type dumpState struct {
+	report *Report
+	json   bool
+	// stack holds the structs being described.
+	stack walkStack
+}
+
+// DumpOption configures Describe and Dump.
+type DumpOption func(*dumpState)
+
+// DumpSources adds the source of every field recorded in report.
+func DumpSources(report *Report) DumpOption {
+	return func(d *dumpState) {
+		d.report = report
+	}
+}
+
+// DumpJSON makes Dump write a JSON array of FieldDescription instead of
+// text.
+func DumpJSON() DumpOption {
+	return func(d *dumpState) {
+		d.json = true
+	}
+}
+
+// Describe lists every field of the struct v, or of the struct v points
+// to, with its value. Nested structs are walked and listed by field path.
+// Fields tagged `secret:"true"` or with the secret option of their env tag
+// are redacted, together with every field below them:
+//
+//	type Config struct {
+//	     Password string `env:"DB_PASSWORD,secret"`
+//	}
+func Describe(v any, opts ...DumpOption) ([]FieldDescription, error) {
+	return newDumpState(opts).describeStruct(v)
+}
+
+// Dump writes the fields listed by Describe to w, one "path = value" line
+// per field followed by its source when known:
+//
+//	Host = 127.0.0.1 (default)
+//	Port = 8080 (env APP_PORT)
+//	Database.Password = ****** (yaml database.password, config.yaml:4)
+//
+// With DumpJSON the fields are written as a JSON array instead.
+func Dump(v any, w io.Writer, opts ...DumpOption) error {
+	d := newDumpState(opts)
+	fields, err := d.describeStruct(v)
+	if err != nil {
+		return err
+	}
+	if d.json {
+		return json.NewEncoder(w).Encode(fields)
+	}
+	for _, field := range fields {
+		line := field.Path + " = " + field.Value
+		if field.Source != "" {
+			line += " (" + describeSource(field) + ")"
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeSource(field FieldDescription) string {
+	source := string(field.Source)
+	if field.Key != "" {
+		source += " " + field.Key
+	}
+	if field.File != "" {
+		source += ", " + field.File
+		if field.Line > 0 {
+			source += ":" + strconv.Itoa(field.Line)
+		}
+	}
+	return source
+}
+
+func newDumpState(opts []DumpOption) *dumpState {
+	d := &dumpState{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func (d *dumpState) describeStruct(v any) ([]FieldDescription, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("expected struct or pointer to struct")
+	}
+	var fields []FieldDescription
+	d.describe(rv, "", false, &fields)
+	return fields, nil
+}
+
+func (d *dumpState) describe(elem reflect.Value, path string, secret bool, fields *[]FieldDescription) {
+	defer d.stack.push(elem)()
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := elem.Field(i)
+		fieldPath := joinPath(path, field.Name)
+		_, envOpts := parseTag(field.Tag.Get("env"))
+		fieldSecret := secret || field.Tag.Get("secret") == "true" || envOpts.contains("secret")
+		if isNestedStruct(field.Type) && !isNullType(field.Type) {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					*fields = append(*fields, d.field(fieldPath, unset))
+					continue
+				}
+				if d.stack.walking(fieldValue) {
+					// A pointer back to an enclosing struct.
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			d.describe(fieldValue, fieldPath, fieldSecret, fields)
+			continue
+		}
+		value, ok := formatValue(fieldValue)
+		if !ok {
+			value = unset
+		} else if fieldSecret {
+			value = redacted
+		}
+		*fields = append(*fields, d.field(fieldPath, value))
+	}
+}
+
+func (d *dumpState) field(path, value string) FieldDescription {
+	description := FieldDescription{Path: path, Value: value}
+	if d.report == nil {
+		return description
+	}
+	if origin, ok := d.report.Lookup(path); ok {
+		description.Source = origin.Source
+		description.Key = origin.Key
+		description.File = origin.File
+		description.Line = origin.Line
+	}
+	return description
+}
+
+// formatValue renders v as text for a dump. It reports false for Null
+// values that are not Valid and nil pointers.
+func formatValue(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if isNullType(v.Type()) {
+		if !v.Field(1).Bool() {
+			return "", false
+		}
+		return formatValue(v.Field(0))
+	}
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	switch value := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err == nil {
+			return string(text), true
+		}
+	case fmt.Stringer:
+		return value.String(), true
+	}
+	return fmt.Sprint(reflect.Indirect(v).Interface()), true
+}
+
+// isNullType reports whether t, or the type t points to, is one of the Null
+// types, whose first field holds the value and second field is Valid.
+func isNullType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Implements(nullableType)
+}
+
+var nullableType = reflect.TypeOf((*nullable)(nil)).Elem()
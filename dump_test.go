@@ -0,0 +1,135 @@
+package gottings
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDump(t *testing.T) {
+	type Database struct {
+		Host     string     `json:"host" env:"TEST_DB_HOST"`
+		Password string     `json:"password" env:"TEST_DB_PASSWORD,secret"`
+		Token    NullString `json:"token" secret:"true"`
+	}
+	type Credentials struct {
+		User string
+	}
+	type Config struct {
+		Host        string              `json:"host" env:"TEST_HOST" default:"127.0.0.1"`
+		Port        NullInt             `json:"port" env:"TEST_PORT"`
+		Timeout     Null[time.Duration] `json:"timeout"`
+		Started     time.Time           `json:"started"`
+		Tags        []string            `json:"tags"`
+		Debug       *bool               `json:"debug"`
+		Database    Database            `json:"database"`
+		Credentials *Credentials        `json:"credentials" secret:"true"`
+		Replica     *Database           `json:"replica"`
+		hidden      string
+	}
+	config := Config{
+		Host:        "127.0.0.1",
+		Port:        NewNullInt(8080),
+		Started:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:        []string{"a", "b"},
+		Database:    Database{Host: "db", Password: "hunter2"},
+		Credentials: &Credentials{User: "admin"},
+		hidden:      "hidden",
+	}
+
+	t.Run("Describe", func(t *testing.T) {
+		fields, err := Describe(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := []FieldDescription{
+			{Path: "Host", Value: "127.0.0.1"},
+			{Path: "Port", Value: "8080"},
+			{Path: "Timeout", Value: "<unset>"},
+			{Path: "Started", Value: "2024-01-02T03:04:05Z"},
+			{Path: "Tags", Value: "[a b]"},
+			{Path: "Debug", Value: "<unset>"},
+			{Path: "Database.Host", Value: "db"},
+			{Path: "Database.Password", Value: "******"},
+			{Path: "Database.Token", Value: "<unset>"},
+			{Path: "Credentials.User", Value: "******"},
+			{Path: "Replica", Value: "<unset>"},
+		}
+		if !reflect.DeepEqual(fields, expected) {
+			t.Errorf("expected %+v, got %+v", expected, fields)
+		}
+	})
+	t.Run("text with sources", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "8080")
+		var report Report
+		loaded := Config{}
+		err := LoadConfiguration([]byte(`{"database": {"host": "db"}}`), &loaded, WithReport(&report))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var buf bytes.Buffer
+		if err := Dump(&loaded, &buf, DumpSources(&report)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := `Host = 127.0.0.1 (default)
+Port = 8080 (env TEST_PORT)
+Timeout = <unset>
+Started = 0001-01-01T00:00:00Z
+Tags = []
+Debug = <unset>
+Database.Host = db (json database.host)
+Database.Password = ******
+Database.Token = <unset>
+Credentials = <unset>
+Replica = <unset>
+`
+		if buf.String() != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+		}
+	})
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Dump(&config, &buf, DumpJSON()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var fields []FieldDescription
+		if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+			t.Fatalf("expected valid JSON, got %v", err)
+		}
+		if len(fields) != 11 || fields[7] != (FieldDescription{Path: "Database.Password", Value: "******"}) {
+			t.Errorf("unexpected fields %+v", fields)
+		}
+		if bytes.Contains(buf.Bytes(), []byte("hunter2")) {
+			t.Errorf("secret leaked in %s", buf.String())
+		}
+	})
+	t.Run("structs shaped like Null types", func(t *testing.T) {
+		type Feature struct {
+			Name  string
+			Valid bool
+		}
+		type Config struct {
+			Feature Feature
+			Secret  NullSecret
+		}
+		fields, err := Describe(Config{Feature: Feature{Name: "beta"}, Secret: NewNullSecret("hunter2")})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := []FieldDescription{
+			{Path: "Feature.Name", Value: "beta"},
+			{Path: "Feature.Valid", Value: "false"},
+			{Path: "Secret", Value: "******"},
+		}
+		if !reflect.DeepEqual(fields, expected) {
+			t.Errorf("result %+v does not match expected %+v", fields, expected)
+		}
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		if _, err := Describe(42); err == nil {
+			t.Fatalf("expected error, got %v", err)
+		}
+	})
+}
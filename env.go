@@ -1,11 +1,19 @@
 package gottings
 
 import (
-	"errors"
+	"encoding"
 	"fmt"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	unmarshalableFieldType = reflect.TypeOf((*UnmarshalableField)(nil)).Elem()
+	textUnmarshalerType    = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType           = reflect.TypeOf(time.Duration(0))
 )
 
 type UnmarshalableField interface {
@@ -19,71 +27,411 @@ type UnmarshalableField interface {
 //
 // config := &Config{}
 // LoadEnv(config)
-func LoadEnv(v any) error {
-	rv := reflect.ValueOf(v)
-	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
-		return errors.New("expected pointer to struct")
+//
+// Nested structs and pointers to structs without an env tag are walked
+// recursively. An envPrefix tag on such a field is prepended to the env
+// keys of every field below it:
+//
+//	type Config struct {
+//	     Database Database `envPrefix:"DB_"`
+//	}
+//
+//	type Database struct {
+//	     Host string `env:"HOST"` // read from DB_HOST
+//	}
+//
+// Slice and array fields are read from a single value split on the
+// envSeparator tag, which defaults to ",":
+//
+//	type Config struct {
+//	     Hosts []string `env:"HOSTS" envSeparator:";"`
+//	}
+//
+// Map fields are read from key/value pairs, e.g. LABELS="team=core,tier=1":
+//
+//	type Config struct {
+//	     Labels map[string]string `env:"LABELS" envKeyValSeparator:"="`
+//	}
+//
+// Fields whose variable is unset and that still hold their zero value are
+// filled from their default tag. Fields marked as required, either with
+// `required:"true"` or with a required option in the env tag, make LoadEnv
+// fail with a *MissingError when they end up unset:
+//
+//	type Config struct {
+//	     Host string `env:"HOST,required"`
+//	     Port int    `env:"PORT" default:"8080"`
+//	}
+//
+// Variables set to an empty string are treated as unset unless the
+// AllowEmpty option or the allowempty tag option is given.
+//
+// With the EnvFiles option, or the file option of a single env tag, a field
+// whose variable is unset is read from the file named by the variable with
+// a _FILE suffix, e.g. DB_PASSWORD_FILE=/run/secrets/db.
+//
+// LoadEnv does not stop at the first invalid value; every failure is
+// reported as a *FieldError in the returned Errors.
+func LoadEnv(v any, opts ...LoadOption) error {
+	return LoadEnvFrom(os.LookupEnv, v, opts...)
+}
+
+// LoadEnvFrom is like LoadEnv but reads variables through lookup instead of
+// the process environment. lookup reports whether the variable is set, as
+// os.LookupEnv does:
+//
+//	err := LoadEnvFrom(MapLookup(map[string]string{"ENV_PORT": "8080"}), config)
+func LoadEnvFrom(lookup func(key string) (string, bool), v any, opts ...LoadOption) error {
+	elem, err := structElem(v)
+	if err != nil {
+		return err
+	}
+	st := newLoadState(opts)
+	st.lookup = lookup
+	return st.loadEnvironment(elem)
+}
+
+// loadEnvironment runs the complete LoadEnv sequence on elem: variables,
+// then defaults, then required fields. Within a Loader only the variables
+// are loaded.
+func (st *loadState) loadEnvironment(elem reflect.Value) error {
+	st.loadEnv(elem, "", "")
+	if !st.layered {
+		st.applyDefaults(elem, "")
+		st.checkRequired(elem)
 	}
-	elem := rv.Elem()
+	return st.err()
+}
 
+// loadEnv populates the fields of elem from the environment, prefixing
+// every env key with prefix. path is the field path of elem. It reports
+// whether any field was set.
+func (st *loadState) loadEnv(elem reflect.Value, prefix, path string) bool {
+	defer st.stack.push(elem)()
+	set := false
 	for i := 0; i < elem.NumField(); i++ {
 		field := elem.Type().Field(i)
-		envKey := field.Tag.Get("env")
+		fieldValue := elem.Field(i)
+		fieldPath := joinPath(path, field.Name)
+		envKey, tagOpts := parseTag(field.Tag.Get("env"))
 		if envKey == "" {
+			if !field.IsExported() || !isNestedStruct(field.Type) {
+				continue
+			}
+			nestedPrefix := prefix + field.Tag.Get("envPrefix")
+			set = st.stack.descend(fieldValue, func(nested reflect.Value) bool {
+				return st.loadEnv(nested, nestedPrefix, fieldPath)
+			}) || set
+			continue
+		}
+		key, envValue, file, ok, err := st.lookupEnv(prefix+envKey, tagOpts)
+		if err != nil {
+			st.addError(&FieldError{Path: fieldPath, Source: st.envSource, Key: key, Err: err})
 			continue
 		}
-		envValue := os.Getenv(envKey)
-		if envValue == "" {
+		if !ok {
 			continue
 		}
 
-		fieldValue := elem.Field(i)
 		if !fieldValue.CanSet() {
-			return fmt.Errorf("cannot set field %s", field.Name)
+			st.addError(&FieldError{Path: fieldPath, Source: st.envSource, Key: key, Err: errUnexported})
+			continue
+		}
+		if err := setEnvValue(fieldValue, envValue, field.Tag); err != nil {
+			st.addError(&FieldError{Path: fieldPath, Source: st.envSource, Key: key, Err: err})
+			continue
 		}
+		st.record(fieldValue, field, Origin{Path: fieldPath, Source: st.envSource, Key: key, File: file})
+		set = true
+	}
+	return set
+}
 
-		var targetValue reflect.Value
-		if fieldValue.Kind() == reflect.Pointer {
-			if fieldValue.IsNil() {
-				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
-			}
-			targetValue = fieldValue.Elem()
-		} else {
-			targetValue = fieldValue
+// lookupEnv reads the variable key. When it is unset and the field reads
+// files, either through the file option of its env tag or the EnvFiles
+// option, the variable key+"_FILE" names a file holding the value. lookupEnv
+// returns the variable actually read, its value, the file the value was
+// read from and whether the field is set.
+func (st *loadState) lookupEnv(key string, tagOpts tagOptions) (string, string, string, bool, error) {
+	allowEmpty := st.allowEmpty || tagOpts.contains("allowempty")
+	value, ok := st.lookup(key)
+	if ok && (value != "" || allowEmpty) {
+		return key, value, st.file, true, nil
+	}
+	if !st.envFiles && !tagOpts.contains("file") {
+		return key, "", "", false, nil
+	}
+	fileKey := key + "_FILE"
+	path, ok := st.lookup(fileKey)
+	if !ok || path == "" {
+		return key, "", "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileKey, "", "", false, fmt.Errorf("cannot read value from file: %w", err)
+	}
+	value = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if value == "" && !allowEmpty {
+		return fileKey, "", "", false, nil
+	}
+	return fileKey, value, path, true, nil
+}
+
+// MapLookup returns a lookup function for LoadEnvFrom that reads variables
+// from m.
+func MapLookup(m map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := m[key]
+		return value, ok
+	}
+}
+
+// setEnvValue converts envValue to the type of fieldValue and stores it,
+// allocating nil pointers on the way. Slices and arrays are split on the
+// envSeparator tag (default ",") and each element is converted on its own.
+// Maps are split the same way into pairs, whose key and value are separated
+// by the envKeyValSeparator tag (default "=").
+func setEnvValue(fieldValue reflect.Value, envValue string, tag reflect.StructTag) error {
+	var targetValue reflect.Value
+	if fieldValue.Kind() == reflect.Pointer {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
 		}
+		targetValue = fieldValue.Elem()
+	} else {
+		targetValue = fieldValue
+	}
 
-		switch targetValue.Kind() {
-		case reflect.Bool:
-			value, err := strconv.ParseBool(envValue)
-			if err != nil {
-				return err
+	if hasUnmarshaler(targetValue.Type()) {
+		return setScalar(targetValue, envValue)
+	}
+	switch targetValue.Kind() {
+	case reflect.Slice:
+		parts := splitEnvValue(envValue, tag)
+		slice := reflect.MakeSlice(targetValue.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setEnvElement(slice.Index(i), part); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		targetValue.Set(slice)
+	case reflect.Array:
+		parts := splitEnvValue(envValue, tag)
+		if len(parts) > targetValue.Len() {
+			return fmt.Errorf("got %d elements for array of length %d", len(parts), targetValue.Len())
+		}
+		array := reflect.New(targetValue.Type()).Elem()
+		for i, part := range parts {
+			if err := setEnvElement(array.Index(i), part); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
 			}
-			targetValue.SetBool(value)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			value, err := strconv.ParseInt(envValue, 10, targetValue.Type().Bits())
-			if err != nil {
-				return err
+		}
+		targetValue.Set(array)
+	case reflect.Map:
+		separator, ok := tag.Lookup("envKeyValSeparator")
+		if !ok {
+			separator = "="
+		}
+		mapType := targetValue.Type()
+		m := reflect.MakeMap(mapType)
+		for i, pair := range splitEnvValue(envValue, tag) {
+			key, value, found := strings.Cut(pair, separator)
+			if !found {
+				return fmt.Errorf("pair %d: missing separator %q in %q", i, separator, pair)
 			}
-			targetValue.SetInt(value)
-		case reflect.String:
-			targetValue.SetString(envValue)
-		case reflect.Float64, reflect.Float32:
-			value, err := strconv.ParseFloat(envValue, targetValue.Type().Bits())
-			if err != nil {
-				return err
+			keyValue := reflect.New(mapType.Key()).Elem()
+			if err := setEnvElement(keyValue, key); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
 			}
-			targetValue.SetFloat(value)
-		case reflect.Struct:
-			unMarshalInterface := reflect.TypeOf((*UnmarshalableField)(nil)).Elem()
-			if fieldValue.Addr().Type().Implements(unMarshalInterface) {
-				unmarshaler := fieldValue.Addr().Interface().(UnmarshalableField)
-				if err := unmarshaler.UnmarshalEnvironmentValue([]byte(envValue)); err != nil {
-					return err
-				}
+			elemValue := reflect.New(mapType.Elem()).Elem()
+			if err := setEnvElement(elemValue, value); err != nil {
+				return fmt.Errorf("value for key %q: %w", key, err)
 			}
-		default:
-			return fmt.Errorf("unexpected field type: %s", field.Type.Kind())
+			m.SetMapIndex(keyValue, elemValue)
 		}
+		targetValue.Set(m)
+	default:
+		return setScalar(targetValue, envValue)
 	}
 	return nil
 }
+
+// setEnvElement converts a single slice, array or map element, which may
+// itself be a pointer.
+func setEnvElement(elemValue reflect.Value, raw string) error {
+	if elemValue.Kind() == reflect.Pointer {
+		elemValue.Set(reflect.New(elemValue.Type().Elem()))
+		elemValue = elemValue.Elem()
+	}
+	return setScalar(elemValue, raw)
+}
+
+// splitEnvValue splits a list or map valued env value on the envSeparator
+// tag.
+func splitEnvValue(envValue string, tag reflect.StructTag) []string {
+	if envValue == "" {
+		return nil
+	}
+	separator, ok := tag.Lookup("envSeparator")
+	if !ok {
+		separator = ","
+	}
+	return strings.Split(envValue, separator)
+}
+
+// setScalar parses raw into targetValue, which must not be a pointer.
+// UnmarshalableField takes precedence over encoding.TextUnmarshaler, and
+// time.Duration is parsed with time.ParseDuration. Complex numbers are
+// written as strconv.ParseComplex expects them, e.g. "1+2i".
+func setScalar(targetValue reflect.Value, raw string) error {
+	switch unmarshaler := targetValue.Addr().Interface().(type) {
+	case UnmarshalableField:
+		return unmarshaler.UnmarshalEnvironmentValue([]byte(raw))
+	case encoding.TextUnmarshaler:
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+	if targetValue.Type() == durationType {
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		targetValue.SetInt(int64(value))
+		return nil
+	}
+
+	switch targetValue.Kind() {
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		targetValue.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, targetValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		targetValue.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value, err := strconv.ParseUint(raw, 10, targetValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		targetValue.SetUint(value)
+	case reflect.String:
+		targetValue.SetString(raw)
+	case reflect.Float64, reflect.Float32:
+		value, err := strconv.ParseFloat(raw, targetValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		targetValue.SetFloat(value)
+	case reflect.Complex64, reflect.Complex128:
+		value, err := strconv.ParseComplex(raw, targetValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		targetValue.SetComplex(value)
+	default:
+		return fmt.Errorf("unexpected field type: %s", targetValue.Kind())
+	}
+	return nil
+}
+
+// isNestedStruct reports whether t, or the type t points to, is a struct
+// that the loaders should walk field by field rather than unmarshal as a
+// single value.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !hasUnmarshaler(t)
+}
+
+// hasUnmarshaler reports whether values of type t parse themselves from a
+// single string and must not be split or walked.
+func hasUnmarshaler(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(unmarshalableFieldType) || pt.Implements(textUnmarshalerType)
+}
+
+// descend calls fn on the struct held by fieldValue. A nil pointer is only
+// replaced by a freshly allocated struct when fn reports that it set
+// something, so that nil keeps meaning "not configured".
+func descend(fieldValue reflect.Value, fn func(reflect.Value) bool) bool {
+	if fieldValue.Kind() != reflect.Pointer {
+		return fn(fieldValue)
+	}
+	if !fieldValue.IsNil() {
+		return fn(fieldValue.Elem())
+	}
+	nested := reflect.New(fieldValue.Type().Elem())
+	set := fn(nested.Elem())
+	if set {
+		fieldValue.Set(nested)
+	}
+	return set
+}
+
+// descendEmbedded is descend for the embedded struct field fieldValue,
+// walking it with walk. A nil pointer to an unexported struct cannot be
+// allocated, so it is only walked into a throwaway struct with a throwaway
+// load state, and a *FieldError is reported, as encoding/json does, when
+// the document would set any of its fields.
+func (st *loadState) descendEmbedded(fieldValue reflect.Value, path string, source Source, key string, walk func(st *loadState, nested reflect.Value) bool) bool {
+	if fieldValue.Kind() != reflect.Pointer || !fieldValue.IsNil() || fieldValue.CanSet() {
+		return descend(fieldValue, func(nested reflect.Value) bool {
+			return walk(st, nested)
+		})
+	}
+	probe := newLoadState(nil)
+	if walk(probe, reflect.New(fieldValue.Type().Elem()).Elem()) || len(probe.errs) > 0 {
+		err := fmt.Errorf("cannot set embedded pointer to unexported struct: %v", fieldValue.Type().Elem())
+		st.addError(&FieldError{Path: path, Source: source, Key: key, Err: err})
+	}
+	return false
+}
+
+// walkStack holds the structs enclosing the field being walked. Walkers
+// that do not stop with their input push the struct they walk, so that
+// self-referential types are walked once rather than forever:
+//
+//	type Node struct {
+//	     Name string `env:"NAME"`
+//	     Next *Node // not walked while a Node is being walked
+//	}
+type walkStack []reflect.Value
+
+// push adds elem to the stack and returns the func removing it.
+func (s *walkStack) push(elem reflect.Value) func() {
+	*s = append(*s, elem)
+	return func() {
+		*s = (*s)[:len(*s)-1]
+	}
+}
+
+// walking reports whether fieldValue leads back to a struct on the stack:
+// it is a nil pointer to the type of such a struct, or points to one.
+func (s walkStack) walking(fieldValue reflect.Value) bool {
+	if fieldValue.Kind() != reflect.Pointer {
+		return false
+	}
+	for _, elem := range s {
+		if elem.Type() != fieldValue.Type().Elem() {
+			continue
+		}
+		if fieldValue.IsNil() || elem.CanAddr() && elem.Addr().Pointer() == fieldValue.Pointer() {
+			return true
+		}
+	}
+	return false
+}
+
+// descend is like the descend function, but skips the structs that are
+// already being walked.
+func (s walkStack) descend(fieldValue reflect.Value, fn func(reflect.Value) bool) bool {
+	if s.walking(fieldValue) {
+		return false
+	}
+	return descend(fieldValue, fn)
+}
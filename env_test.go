@@ -1,8 +1,16 @@
 package gottings
 
 import (
+	"errors"
+	"io/fs"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadEnv(t *testing.T) {
@@ -137,8 +145,449 @@ func TestLoadEnv(t *testing.T) {
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if reflect.DeepEqual(config2, expected2) {
+		if !reflect.DeepEqual(config2, expected2) {
 			t.Errorf("result %v does not match expected %v\n", config2, expected2)
 		}
 	})
 }
+
+func TestLoadEnvNested(t *testing.T) {
+	type Database struct {
+		Host string  `env:"HOST"`
+		Port NullInt `env:"PORT"`
+	}
+	type Cache struct {
+		Size int `env:"SIZE"`
+	}
+	type HTTP struct {
+		Addr  string `env:"ADDR"`
+		Cache *Cache `envPrefix:"CACHE_"`
+	}
+	type Config struct {
+		Name     string   `env:"TEST_NAME"`
+		Database Database `envPrefix:"TEST_DB_"`
+		HTTP     *HTTP    `envPrefix:"TEST_HTTP_"`
+		Unused   *Cache   `envPrefix:"TEST_UNUSED_"`
+	}
+	t.Setenv("TEST_NAME", "app")
+	t.Setenv("TEST_DB_HOST", "db.local")
+	t.Setenv("TEST_DB_PORT", "5432")
+	t.Setenv("TEST_HTTP_ADDR", ":8080")
+	t.Setenv("TEST_HTTP_CACHE_SIZE", "64")
+
+	config := Config{}
+	err := LoadEnv(&config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := Config{
+		Name:     "app",
+		Database: Database{Host: "db.local", Port: NewNullInt(5432)},
+		HTTP:     &HTTP{Addr: ":8080", Cache: &Cache{Size: 64}},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("result %+v does not match expected %+v\n", config, expected)
+	}
+	if config.Unused != nil {
+		t.Errorf("expected nested pointer without values to stay nil, got %+v", config.Unused)
+	}
+}
+
+func TestLoadEnvSlices(t *testing.T) {
+	type Config struct {
+		Strings  []string   `env:"TEST_STRINGS"`
+		Ints     []int      `env:"TEST_INTS" envSeparator:";"`
+		Floats   [3]float64 `env:"TEST_FLOATS"`
+		Bools    *[]bool    `env:"TEST_BOOLS"`
+		Nulls    []NullInt8 `env:"TEST_NULLS"`
+		Pointers []*int     `env:"TEST_POINTERS"`
+		Spaced   []string   `env:"TEST_SPACED" envSeparator:" "`
+		Unset    []string   `env:"TEST_UNSET"`
+	}
+	t.Setenv("TEST_STRINGS", "a,b,c")
+	t.Setenv("TEST_INTS", "1;2;3")
+	t.Setenv("TEST_FLOATS", "1.5,2.5")
+	t.Setenv("TEST_BOOLS", "true,false")
+	t.Setenv("TEST_NULLS", "1,2")
+	t.Setenv("TEST_POINTERS", "7")
+	t.Setenv("TEST_SPACED", "x y")
+
+	config := Config{}
+	if err := LoadEnv(&config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	seven := 7
+	bools := []bool{true, false}
+	expected := Config{
+		Strings:  []string{"a", "b", "c"},
+		Ints:     []int{1, 2, 3},
+		Floats:   [3]float64{1.5, 2.5, 0},
+		Bools:    &bools,
+		Nulls:    []NullInt8{NewNullInt8(1), NewNullInt8(2)},
+		Pointers: []*int{&seven},
+		Spaced:   []string{"x", "y"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("result %+v does not match expected %+v\n", config, expected)
+	}
+
+	t.Run("invalid element", func(t *testing.T) {
+		t.Setenv("TEST_INTS", "1;x;3")
+		err := LoadEnv(&Config{})
+		if err == nil || !strings.Contains(err.Error(), "element 1") {
+			t.Fatalf("expected error naming element 1, got %v", err)
+		}
+	})
+	t.Run("array too short", func(t *testing.T) {
+		t.Setenv("TEST_FLOATS", "1,2,3,4")
+		if err := LoadEnv(&Config{}); err == nil {
+			t.Fatalf("expected error, got %v", err)
+		}
+	})
+}
+
+func TestLoadEnvMaps(t *testing.T) {
+	type Config struct {
+		Labels    map[string]string   `env:"TEST_LABELS"`
+		Limits    map[string]int      `env:"TEST_LIMITS" envSeparator:";" envKeyValSeparator:":"`
+		Weights   map[int]float64     `env:"TEST_WEIGHTS"`
+		Overrides *map[string]NullInt `env:"TEST_OVERRIDES"`
+		Unset     map[string]string   `env:"TEST_UNSET"`
+	}
+	t.Setenv("TEST_LABELS", "team=core,tier=backend")
+	t.Setenv("TEST_LIMITS", "a:1;b:2")
+	t.Setenv("TEST_WEIGHTS", "1=0.5,2=0.25")
+	t.Setenv("TEST_OVERRIDES", "tenant=3")
+
+	config := Config{}
+	if err := LoadEnv(&config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	overrides := map[string]NullInt{"tenant": NewNullInt(3)}
+	expected := Config{
+		Labels:    map[string]string{"team": "core", "tier": "backend"},
+		Limits:    map[string]int{"a": 1, "b": 2},
+		Weights:   map[int]float64{1: 0.5, 2: 0.25},
+		Overrides: &overrides,
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("result %+v does not match expected %+v\n", config, expected)
+	}
+
+	testCases := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "missing separator", key: "TEST_LABELS", value: "team"},
+		{name: "invalid value", key: "TEST_LIMITS", value: "a:x"},
+		{name: "invalid key", key: "TEST_WEIGHTS", value: "x=0.5"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(tc.key, tc.value)
+			if err := LoadEnv(&Config{}); err == nil {
+				t.Fatalf("expected error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadEnvTextTypes(t *testing.T) {
+	type Config struct {
+		Timeout     time.Duration       `env:"TEST_TIMEOUT"`
+		NullTimeout Null[time.Duration] `env:"TEST_NULLTIMEOUT"`
+		Start       time.Time           `env:"TEST_START"`
+		Addrs       []netip.Addr        `env:"TEST_ADDRS"`
+	}
+	t.Setenv("TEST_TIMEOUT", "1m")
+	t.Setenv("TEST_NULLTIMEOUT", "2s")
+	t.Setenv("TEST_START", "2024-01-02T03:04:05Z")
+	t.Setenv("TEST_ADDRS", "10.0.0.1,::1")
+
+	config := Config{}
+	if err := LoadEnv(&config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := Config{
+		Timeout:     time.Minute,
+		NullTimeout: NewNull(2 * time.Second),
+		Start:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Addrs:       []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.IPv6Loopback()},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("result %+v does not match expected %+v\n", config, expected)
+	}
+}
+
+func TestLoadEnvUnsigned(t *testing.T) {
+	type Config struct {
+		Uint       uint             `env:"TEST_UINT"`
+		NullUint   NullUint         `env:"TEST_NULLUINT"`
+		Uint8      uint8            `env:"TEST_UINT8"`
+		NullUint8  NullUint8        `env:"TEST_NULLUINT8"`
+		Uint16     uint16           `env:"TEST_UINT16"`
+		NullUint16 NullUint16       `env:"TEST_NULLUINT16"`
+		Uint32     uint32           `env:"TEST_UINT32"`
+		NullUint32 NullUint32       `env:"TEST_NULLUINT32"`
+		Uint64     *uint64          `env:"TEST_UINT64"`
+		NullUint64 NullUint64       `env:"TEST_NULLUINT64"`
+		Uintptr    uintptr          `env:"TEST_UINTPTR"`
+		Ports      []uint16         `env:"TEST_PORTS"`
+		Complex64  complex64        `env:"TEST_COMPLEX64"`
+		Complex128 complex128       `env:"TEST_COMPLEX128"`
+		Masks      map[string]uint8 `env:"TEST_MASKS"`
+	}
+	t.Setenv("TEST_UINT", "1")
+	t.Setenv("TEST_NULLUINT", "1")
+	t.Setenv("TEST_UINT8", "255")
+	t.Setenv("TEST_NULLUINT8", "255")
+	t.Setenv("TEST_UINT16", "8080")
+	t.Setenv("TEST_NULLUINT16", "8080")
+	t.Setenv("TEST_UINT32", "4294967295")
+	t.Setenv("TEST_NULLUINT32", "4294967295")
+	t.Setenv("TEST_UINT64", "18446744073709551615")
+	t.Setenv("TEST_NULLUINT64", "18446744073709551615")
+	t.Setenv("TEST_UINTPTR", "64")
+	t.Setenv("TEST_PORTS", "80,443")
+	t.Setenv("TEST_COMPLEX64", "1.5-2i")
+	t.Setenv("TEST_COMPLEX128", "(3+4i)")
+	t.Setenv("TEST_MASKS", "read=4,write=2")
+
+	config := Config{}
+	if err := LoadEnv(&config); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	maxUint64 := uint64(math.MaxUint64)
+	expected := Config{
+		Uint:       1,
+		NullUint:   NewNullUint(1),
+		Uint8:      255,
+		NullUint8:  NewNullUint8(255),
+		Uint16:     8080,
+		NullUint16: NewNullUint16(8080),
+		Uint32:     4294967295,
+		NullUint32: NewNullUint32(4294967295),
+		Uint64:     &maxUint64,
+		NullUint64: NewNullUint64(maxUint64),
+		Uintptr:    64,
+		Ports:      []uint16{80, 443},
+		Complex64:  complex(1.5, -2),
+		Complex128: complex(3, 4),
+		Masks:      map[string]uint8{"read": 4, "write": 2},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("result %+v does not match expected %+v\n", config, expected)
+	}
+
+	testCases := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "negative", key: "TEST_UINT", value: "-1"},
+		{name: "overflow", key: "TEST_UINT8", value: "256"},
+		{name: "float", key: "TEST_UINT16", value: "1.5"},
+		{name: "invalid complex", key: "TEST_COMPLEX128", value: "1+2j"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(tc.key, tc.value)
+			err := LoadEnv(&Config{})
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Key != tc.key {
+				t.Fatalf("expected *FieldError for %s, got %v", tc.key, err)
+			}
+		})
+	}
+}
+
+func TestLoadEnvSelfReferential(t *testing.T) {
+	type Node struct {
+		Name  string `env:"TEST_NAME" default:"node"`
+		Level int    `env:"TEST_LEVEL" required:"true"`
+		Next  *Node
+	}
+	t.Setenv("TEST_LEVEL", "1")
+
+	t.Run("nil pointers stay nil", func(t *testing.T) {
+		node := Node{}
+		if err := LoadEnv(&node); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if node.Name != "node" || node.Level != 1 || node.Next != nil {
+			t.Errorf("unexpected result %+v", node)
+		}
+	})
+	t.Run("cycles are walked once", func(t *testing.T) {
+		node := Node{}
+		node.Next = &node
+		if err := LoadEnv(&node); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if node.Level != 1 || node.Next != &node {
+			t.Errorf("unexpected result %+v", node)
+		}
+	})
+	t.Run("missing fields", func(t *testing.T) {
+		err := LoadEnvFrom(MapLookup(nil), &Node{})
+		var missing *MissingError
+		if !errors.As(err, &missing) || !reflect.DeepEqual(missing.Keys, []string{"TEST_LEVEL"}) {
+			t.Fatalf("expected TEST_LEVEL to be missing, got %v", err)
+		}
+	})
+}
+
+func TestLoadEnvEmptyValues(t *testing.T) {
+	type Config struct {
+		Host       string     `json:"host" env:"TEST_HOST"`
+		NullString NullString `json:"null_string" env:"TEST_NULLSTRING"`
+		Tagged     string     `json:"tagged" env:"TEST_TAGGED,allowempty"`
+		Port       int        `json:"port" env:"TEST_PORT"`
+	}
+	t.Setenv("TEST_HOST", "")
+	t.Setenv("TEST_NULLSTRING", "")
+	t.Setenv("TEST_TAGGED", "")
+	data := []byte(`{"host": "127.0.0.1", "null_string": "value", "tagged": "value", "port": 8080}`)
+
+	t.Run("empty values are unset by default", func(t *testing.T) {
+		config := Config{}
+		if err := LoadConfiguration(data, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Config{Host: "127.0.0.1", NullString: NewNullString("value"), Port: 8080}
+		if config != expected {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("AllowEmpty", func(t *testing.T) {
+		config := Config{}
+		if err := LoadConfiguration(data, &config, AllowEmpty()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Config{NullString: NewNullString(""), Port: 8080}
+		if config != expected {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("AllowEmpty - invalid empty value", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "")
+		if err := LoadEnv(&Config{}, AllowEmpty()); err == nil {
+			t.Fatalf("expected error, got %v", err)
+		}
+	})
+}
+
+func TestLoadEnvFrom(t *testing.T) {
+	type Database struct {
+		Host string `env:"HOST"`
+	}
+	type Config struct {
+		Port     NullInt  `env:"PORT"`
+		Tags     []string `env:"TAGS"`
+		Database Database `envPrefix:"DB_"`
+	}
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected Config
+	}{
+		{
+			name: "all set",
+			env:  map[string]string{"PORT": "8080", "TAGS": "a,b", "DB_HOST": "db"},
+			expected: Config{
+				Port:     NewNullInt(8080),
+				Tags:     []string{"a", "b"},
+				Database: Database{Host: "db"},
+			},
+		},
+		{
+			name:     "partially set",
+			env:      map[string]string{"PORT": "1312"},
+			expected: Config{Port: NewNullInt(1312)},
+		},
+		{
+			name: "empty",
+			env:  map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			config := Config{}
+			if err := LoadEnvFrom(MapLookup(tc.env), &config); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(config, tc.expected) {
+				t.Errorf("result %+v does not match expected %+v\n", config, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLoadEnvFiles(t *testing.T) {
+	type Config struct {
+		Password Secret  `env:"PASSWORD,file"`
+		Port     NullInt `env:"PORT"`
+		User     string  `env:"USER"`
+	}
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			panic(err)
+		}
+		return path
+	}
+	password := write("password", "hunter2\n")
+	port := write("port", "8080\r\n")
+	user := write("user", "admin\n")
+
+	t.Run("per field", func(t *testing.T) {
+		config := Config{}
+		env := map[string]string{"PASSWORD_FILE": password, "PORT_FILE": port}
+		if err := LoadEnvFrom(MapLookup(env), &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Password.Reveal() != "hunter2" {
+			t.Errorf("Expected hunter2 got %q", config.Password.Reveal())
+		}
+		if config.Port.Valid {
+			t.Errorf("expected PORT_FILE to be ignored without the file option")
+		}
+	})
+	t.Run("per call", func(t *testing.T) {
+		var report Report
+		config := Config{}
+		env := map[string]string{"PORT_FILE": port, "USER": "root", "USER_FILE": user}
+		if err := LoadEnvFrom(MapLookup(env), &config, EnvFiles(), WithReport(&report)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port != NewNullInt(8080) {
+			t.Errorf("Expected 8080 got %+v", config.Port)
+		}
+		if config.User != "root" {
+			t.Errorf("Expected the variable to take precedence over the file, got %s", config.User)
+		}
+		origin, _ := report.Lookup("Port")
+		if origin.Key != "PORT_FILE" || origin.File != port {
+			t.Errorf("unexpected origin %+v", origin)
+		}
+	})
+	t.Run("unreadable file", func(t *testing.T) {
+		env := map[string]string{"PASSWORD_FILE": filepath.Join(dir, "missing"), "PORT_FILE": write("bad", "http\n")}
+		err := LoadEnvFrom(MapLookup(env), &Config{}, EnvFiles())
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", err)
+		}
+		var fieldErr *FieldError
+		if !errors.As(errs[0], &fieldErr) || fieldErr.Key != "PASSWORD_FILE" || !errors.Is(fieldErr, fs.ErrNotExist) {
+			t.Errorf("expected a read error for PASSWORD_FILE, got %v", errs[0])
+		}
+		if !errors.As(errs[1], &fieldErr) || fieldErr.Key != "PORT_FILE" {
+			t.Errorf("expected a conversion error for PORT_FILE, got %v", errs[1])
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package gottings
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Source identifies where a configuration value comes from.
+type Source string
+
+const (
+	SourceEnv        Source = "env"
+	SourceJSON       Source = "json"
+	SourceOption     Source = "option"
+	SourceFlag       Source = "flag"
+	SourceDefault    Source = "default"
+	SourceDotEnv     Source = "dotenv"
+	SourceYAML       Source = "yaml"
+	SourceTOML       Source = "toml"
+	SourceINI        Source = "ini"
+	SourceProperties Source = "properties"
+)
+
+// ErrMissing is matched by the error returned when required fields were not
+// set by any source.
+var ErrMissing = errors.New("missing required configuration")
+
+// Errors collects every error encountered while loading a configuration.
+// It supports errors.Is and errors.As through its Unwrap method.
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// FieldError reports a value that could not be stored into a field.
+type FieldError struct {
+	// Path is the dotted path of the field, e.g. "Database.Port".
+	Path string
+	// Source is where the value came from.
+	Source Source
+	// Key is the name of the value in its source: the environment variable,
+	// the option name or the JSON key path. It is empty for defaults.
+	Key string
+	// Line and Column locate the value in its file, when known.
+	Line   int
+	Column int
+	// Err is the underlying conversion error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	from := string(e.Source)
+	if e.Key != "" {
+		from += " " + e.Key
+	}
+	if e.Line > 0 {
+		from += fmt.Sprintf(" at line %d, column %d", e.Line, e.Column)
+	}
+	return fmt.Sprintf("failed to set field %s from %s: %v", e.Path, from, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// SyntaxError reports malformed input in a configuration file.
+type SyntaxError struct {
+	// File is the path of the file, when known.
+	File string
+	// Line is the 1-based line of the error.
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// MissingError lists the required fields that were not set. Keys holds the
+// environment key of each field, or its field path when it has none.
+type MissingError struct {
+	Keys []string
+}
+
+func (e *MissingError) Error() string {
+	return ErrMissing.Error() + ": " + strings.Join(e.Keys, ", ")
+}
+
+func (e *MissingError) Unwrap() error {
+	return ErrMissing
+}
@@ -0,0 +1,216 @@
+package gottings
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRequired(t *testing.T) {
+	type Database struct {
+		Host string `env:"HOST,required" json:"host"`
+	}
+	type Config struct {
+		Host     string   `env:"TEST_HOST,required" json:"host"`
+		Port     NullInt  `env:"TEST_PORT" json:"port" required:"true"`
+		Name     string   `env:"TEST_NAME,required" default:"app"`
+		Token    string   `json:"token" required:"true"`
+		Database Database `json:"database" envPrefix:"TEST_DB_"`
+	}
+	t.Run("LoadEnv - all missing keys listed", func(t *testing.T) {
+		err := LoadEnv(&Config{})
+		var missing *MissingError
+		if !errors.As(err, &missing) {
+			t.Fatalf("expected *MissingError, got %v", err)
+		}
+		expected := []string{"TEST_HOST", "TEST_PORT", "Token", "TEST_DB_HOST"}
+		if !reflect.DeepEqual(missing.Keys, expected) {
+			t.Fatalf("expected missing keys %v, got %v", expected, missing.Keys)
+		}
+		if !errors.Is(err, ErrMissing) {
+			t.Fatalf("expected errors.Is(err, ErrMissing), got %v", err)
+		}
+	})
+	t.Run("LoadEnv - explicit zero satisfies required", func(t *testing.T) {
+		t.Setenv("TEST_HOST", "localhost")
+		t.Setenv("TEST_PORT", "0")
+		t.Setenv("TEST_DB_HOST", "db")
+		config := Config{Token: "secret"}
+		if err := LoadEnv(&config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+	t.Run("LoadConfiguration", func(t *testing.T) {
+		t.Setenv("TEST_HOST", "localhost")
+		data := []byte(`{"port": 8080, "database": {"host": "db"}}`)
+		err := LoadConfiguration(data, &Config{})
+		var missing *MissingError
+		if !errors.As(err, &missing) {
+			t.Fatalf("expected *MissingError, got %v", err)
+		}
+		if !reflect.DeepEqual(missing.Keys, []string{"Token"}) {
+			t.Fatalf("expected missing keys [Token], got %v", missing.Keys)
+		}
+	})
+	t.Run("LoadConfiguration - null does not satisfy required", func(t *testing.T) {
+		type Config struct {
+			Port NullInt `json:"port" required:"true"`
+			Host *string `json:"host" required:"true"`
+		}
+		err := LoadConfiguration([]byte(`{"port": null, "host": null}`), &Config{})
+		var missing *MissingError
+		if !errors.As(err, &missing) {
+			t.Fatalf("expected *MissingError, got %v", err)
+		}
+		expected := []string{"Port", "Host"}
+		if !reflect.DeepEqual(missing.Keys, expected) {
+			t.Fatalf("expected missing keys %v, got %v", expected, missing.Keys)
+		}
+	})
+	t.Run("LoadConfiguration - required nested struct", func(t *testing.T) {
+		type Database struct {
+			Host string `json:"host"`
+		}
+		type Config struct {
+			Database Database  `json:"database" required:"true"`
+			Replica  *Database `json:"replica" required:"true"`
+		}
+		err := LoadConfiguration([]byte(`{}`), &Config{})
+		var missing *MissingError
+		if !errors.As(err, &missing) {
+			t.Fatalf("expected *MissingError, got %v", err)
+		}
+		expected := []string{"Database", "Replica"}
+		if !reflect.DeepEqual(missing.Keys, expected) {
+			t.Fatalf("expected missing keys %v, got %v", expected, missing.Keys)
+		}
+		data := []byte(`{"database": {"host": ""}, "replica": {"host": "db"}}`)
+		if err := LoadConfiguration(data, &Config{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestErrorsAggregated(t *testing.T) {
+	type Config struct {
+		Port    int     `env:"TEST_PORT"`
+		Ratio   float64 `env:"TEST_RATIO"`
+		Enabled bool    `env:"TEST_ENABLED"`
+		Host    string  `env:"TEST_HOST,required"`
+	}
+	t.Setenv("TEST_PORT", "http")
+	t.Setenv("TEST_RATIO", "half")
+	t.Setenv("TEST_ENABLED", "yes")
+
+	err := LoadEnv(&Config{})
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %v", err)
+	}
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %d: %v", len(errs), err)
+	}
+	if !errors.Is(err, ErrMissing) {
+		t.Fatalf("expected errors.Is(err, ErrMissing), got %v", err)
+	}
+	for _, key := range []string{"TEST_PORT", "TEST_RATIO", "TEST_ENABLED", "TEST_HOST"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected error message to mention %s, got %v", key, err)
+		}
+	}
+}
+
+func TestFieldError(t *testing.T) {
+	type Database struct {
+		Port NullInt `json:"port" env:"PORT"`
+	}
+	type Config struct {
+		Port     int      `json:"port" env:"TEST_PORT"`
+		Ratio    float64  `json:"ratio" default:"half"`
+		Database Database `json:"database" envPrefix:"TEST_DB_"`
+	}
+	testCases := []struct {
+		name     string
+		load     func(t *testing.T) error
+		expected []FieldError
+	}{
+		{
+			name: "env",
+			load: func(t *testing.T) error {
+				t.Setenv("TEST_PORT", "abc")
+				t.Setenv("TEST_DB_PORT", "def")
+				return LoadEnv(&Config{Ratio: 1})
+			},
+			expected: []FieldError{
+				{Path: "Port", Source: SourceEnv, Key: "TEST_PORT"},
+				{Path: "Database.Port", Source: SourceEnv, Key: "TEST_DB_PORT"},
+			},
+		},
+		{
+			name: "json",
+			load: func(t *testing.T) error {
+				return LoadConfiguration([]byte(`{"port": "abc", "database": {"port": "def"}}`), &Config{Ratio: 1})
+			},
+			expected: []FieldError{
+				{Path: "Port", Source: SourceJSON, Key: "port"},
+				{Path: "Database.Port", Source: SourceJSON, Key: "database.port"},
+			},
+		},
+		{
+			name: "option",
+			load: func(t *testing.T) error {
+				return LoadOptions(Options{"Port": "abc", "Ratio": 1.0}, &Config{})
+			},
+			expected: []FieldError{
+				{Path: "Port", Source: SourceOption, Key: "Port"},
+			},
+		},
+		{
+			name: "default",
+			load: func(t *testing.T) error {
+				return LoadEnv(&Config{})
+			},
+			expected: []FieldError{
+				{Path: "Ratio", Source: SourceDefault},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var errs Errors
+			if !errors.As(tc.load(t), &errs) {
+				t.Fatalf("expected Errors")
+			}
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors, got %v", len(tc.expected), errs)
+			}
+			for i, err := range errs {
+				var fieldErr *FieldError
+				if !errors.As(err, &fieldErr) {
+					t.Fatalf("expected *FieldError, got %v", err)
+				}
+				got := FieldError{Path: fieldErr.Path, Source: fieldErr.Source, Key: fieldErr.Key}
+				if got != tc.expected[i] {
+					t.Errorf("expected %+v, got %+v", tc.expected[i], got)
+				}
+				if fieldErr.Err == nil {
+					t.Errorf("expected underlying error for %s", fieldErr.Path)
+				}
+			}
+		})
+	}
+
+	t.Run("unwraps to the conversion error", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "abc")
+		err := LoadEnv(&Config{Ratio: 1})
+		if !errors.Is(err, strconv.ErrSyntax) {
+			t.Fatalf("expected errors.Is(err, strconv.ErrSyntax), got %v", err)
+		}
+		expected := `failed to set field Port from env TEST_PORT: strconv.ParseInt: parsing "abc": invalid syntax`
+		if err.Error() != expected {
+			t.Fatalf("expected message %q, got %q", expected, err.Error())
+		}
+	})
+}
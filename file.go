@@ -0,0 +1,155 @@
+package gottings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// LoadFile reads the configuration file at path and populates v from it
+// and then from the environment, as LoadConfiguration does:
+//
+//	config := &Config{}
+//	if err := gottings.LoadFile("config.yaml", config); err != nil {
+//	     log.Fatal(err)
+//	}
+//
+// The format is chosen from the file extension: .json, .yaml and .yml,
+// .toml, .ini, .properties and .env (as well as names starting with .env,
+// such as .env.local). Files with any other extension are recognised from
+// their content. Variables of a .env file fill the fields whose env key
+// they name; the process environment still overrides them.
+//
+// Errors reading or parsing the file mention path.
+func LoadFile(path string, v any, opts ...LoadOption) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("load configuration %s: %w", path, err)
+	}
+	return loadFile(path, data, v, opts)
+}
+
+// LoadFileFS is like LoadFile but reads path from fsys.
+func LoadFileFS(fsys fs.FS, path string, v any, opts ...LoadOption) error {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return fmt.Errorf("load configuration %s: %w", path, err)
+	}
+	return loadFile(path, data, v, opts)
+}
+
+func loadFile(path string, data []byte, v any, opts []LoadOption) error {
+	decode := detectFormat(path, data)
+	return loadConfiguration(data, v, opts, func(st *loadState, elem reflect.Value, data []byte) error {
+		st.file = path
+		err := decode(st, elem, data)
+		st.file = ""
+		if syntaxErr, ok := err.(*SyntaxError); ok {
+			syntaxErr.File = path
+			return syntaxErr
+		}
+		if err != nil {
+			return fmt.Errorf("load configuration %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// detectFormat returns the decoder for the file at path, from its extension
+// or, when the extension is unknown, from its content.
+func detectFormat(path string, data []byte) documentDecoder {
+	base := filepath.Base(path)
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return (*loadState).loadDotEnvDocument
+	}
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".json":
+		return (*loadState).loadJSONDocument
+	case ".yaml", ".yml":
+		return (*loadState).loadYAMLDocument
+	case ".toml":
+		return (*loadState).loadTOMLDocument
+	case ".ini":
+		return (*loadState).loadINIDocument
+	case ".properties":
+		return (*loadState).loadPropertiesDocument
+	case ".env":
+		return (*loadState).loadDotEnvDocument
+	}
+	return sniffFormat(data)
+}
+
+// sniffFormat guesses the format of data. JSON is recognised by its
+// validity, dotenv by lines of the form KEY=value, TOML by its validity and
+// INI by lines that are sections or contain '='. Anything else is read as
+// YAML.
+func sniffFormat(data []byte) documentDecoder {
+	if json.Valid(data) {
+		return (*loadState).loadJSONDocument
+	}
+	lines := significantLines(data)
+	if len(lines) == 0 {
+		return (*loadState).loadYAMLDocument
+	}
+	if allLines(lines, isDotEnvLine) {
+		return (*loadState).loadDotEnvDocument
+	}
+	if _, err := toml.Decode(string(data), &map[string]any{}); err == nil {
+		return (*loadState).loadTOMLDocument
+	}
+	if allLines(lines, isINILine) {
+		return (*loadState).loadINIDocument
+	}
+	return (*loadState).loadYAMLDocument
+}
+
+// significantLines returns the trimmed lines of data that are neither blank
+// nor comments.
+func significantLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(bytes.TrimPrefix(data, []byte("\ufeff"))), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func allLines(lines []string, fn func(string) bool) bool {
+	for _, line := range lines {
+		if !fn(line) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDotEnvLine(line string) bool {
+	line = strings.TrimPrefix(line, "export ")
+	key, _, found := strings.Cut(line, "=")
+	if !found || key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if !isDotEnvKeyByte(key[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isINILine(line string) bool {
+	if line[0] == '[' {
+		return strings.HasSuffix(line, "]")
+	}
+	return strings.Contains(line, "=")
+}
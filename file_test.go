@@ -0,0 +1,99 @@
+package gottings
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadFile(t *testing.T) {
+	type Config struct {
+		Host string `json:"host" env:"APP_HOST"`
+		Port int    `json:"port" env:"TEST_PORT"`
+	}
+
+	t.Run("format from extension", func(t *testing.T) {
+		for _, path := range []string{
+			"testdata/info.json",
+			"testdata/info.yaml",
+			"testdata/info.toml",
+			"testdata/info.ini",
+			"testdata/info.properties",
+		} {
+			config := Config{}
+			if err := LoadFile(path, &config); err != nil {
+				t.Fatalf("%s: expected error to be nil, got %s", path, err)
+			}
+			expected := Config{Host: "127.0.0.1", Port: 8000}
+			if config != expected {
+				t.Errorf("%s: result %+v does not match expected %+v", path, config, expected)
+			}
+		}
+	})
+	t.Run("dotenv", func(t *testing.T) {
+		config := Config{}
+		if err := LoadFile("testdata/app.env", &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if config.Host != "127.0.0.1" {
+			t.Errorf("Expected 127.0.0.1 got config.Host=%s\n", config.Host)
+		}
+	})
+	t.Run("environment overrides the file", func(t *testing.T) {
+		t.Setenv("APP_HOST", "env.example.com")
+		t.Setenv("TEST_PORT", "9000")
+		for _, path := range []string{"testdata/info.json", "testdata/app.env"} {
+			config := Config{}
+			if err := LoadFile(path, &config); err != nil {
+				t.Fatalf("%s: expected error to be nil, got %s", path, err)
+			}
+			expected := Config{Host: "env.example.com", Port: 9000}
+			if config != expected {
+				t.Errorf("%s: result %+v does not match expected %+v", path, config, expected)
+			}
+		}
+	})
+	t.Run("format from content", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"json":   {Data: []byte(`{"host": "json"}`)},
+			"yaml":   {Data: []byte("# comment\nhost: yaml\n")},
+			"toml":   {Data: []byte("host = \"toml\"\n")},
+			"ini":    {Data: []byte("; comment\nhost = ini\n")},
+			"dotenv": {Data: []byte("# comment\nexport APP_HOST=dotenv\n")},
+		}
+		for name := range fsys {
+			config := Config{}
+			if err := LoadFileFS(fsys, name, &config); err != nil {
+				t.Fatalf("%s: expected error to be nil, got %s", name, err)
+			}
+			if config.Host != name {
+				t.Errorf("expected %s got config.Host=%s\n", name, config.Host)
+			}
+		}
+	})
+	t.Run("errors mention the path", func(t *testing.T) {
+		err := LoadFile("testdata/missing.json", &Config{})
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("expected fs.ErrNotExist, got %v", err)
+		}
+		if err.Error() != "load configuration testdata/missing.json: open testdata/missing.json: no such file or directory" {
+			t.Errorf("unexpected error message %q", err)
+		}
+
+		fsys := fstest.MapFS{
+			"config.ini":  {Data: []byte("[database\n")},
+			"config.json": {Data: []byte(`{"host": `)},
+		}
+		err = LoadFileFS(fsys, "config.ini", &Config{})
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.File != "config.ini" {
+			t.Errorf("expected *SyntaxError in config.ini, got %v", err)
+		}
+		err = LoadFileFS(fsys, "config.json", &Config{})
+		if err == nil || !strings.HasPrefix(err.Error(), "load configuration config.json: ") {
+			t.Errorf("expected error mentioning config.json, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package gottings
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// NewFlagSet returns a FlagSet named name with a flag for every field of
+// the struct v points to that has a flag tag, as RegisterFlags defines
+// them.
+func NewFlagSet(name string, v any, errorHandling flag.ErrorHandling) (*flag.FlagSet, error) {
+	flags := flag.NewFlagSet(name, errorHandling)
+	if err := RegisterFlags(flags, v); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// RegisterFlags defines a flag in flags for every field of the struct v
+// points to that has a flag tag. The usage tag gives the help text:
+//
+//	type Config struct {
+//	     Port     int           `env:"APP_PORT" flag:"port" usage:"port to listen on"`
+//	     Timeout  time.Duration `flag:"timeout" default:"30s"`
+//	     Database Database      `flag:"db"` // -db.host, -db.port, ...
+//	}
+//
+// bool, string, int, int64, uint, uint64, float64 and time.Duration fields
+// become flags of the matching kind; fields of any other supported type
+// parse their value as LoadEnv does. Nested structs are walked, and the
+// flag tag of a nested struct prefixes the names of the flags below it.
+//
+// The current value of each field, or its default tag when it is zero, is
+// shown as the default of the flag, so v should be loaded from the other
+// sources first. Secret fields never show their value. Registering flags
+// does not modify v: apply the parsed flags with LoadFlags.
+func RegisterFlags(flags *flag.FlagSet, v any) error {
+	elem, err := structElem(v)
+	if err != nil {
+		return err
+	}
+	var errs Errors
+	walkFlags(new(walkStack), elem, "", "", func(name, path string, field reflect.StructField, fieldValue reflect.Value) bool {
+		if flags.Lookup(name) != nil {
+			errs = append(errs, fmt.Errorf("field %s: flag redefined: %s", path, name))
+			return false
+		}
+		value := reflect.New(field.Type).Elem()
+		value.Set(fieldValue)
+		if def, ok := field.Tag.Lookup("default"); ok && value.IsZero() {
+			if err := setEnvValue(value, def, field.Tag); err != nil {
+				errs = append(errs, &FieldError{Path: path, Source: SourceDefault, Err: fmt.Errorf("invalid default %q: %w", def, err)})
+				return false
+			}
+		}
+		usage := field.Tag.Get("usage")
+		if isSecretField(field) {
+			flags.Var(&fieldFlag{value: value, tag: field.Tag, secret: true}, name, usage)
+			return false
+		}
+		switch field.Type {
+		case reflect.TypeOf(false):
+			flags.Bool(name, value.Bool(), usage)
+		case reflect.TypeOf(""):
+			flags.String(name, value.String(), usage)
+		case reflect.TypeOf(0):
+			flags.Int(name, int(value.Int()), usage)
+		case reflect.TypeOf(int64(0)):
+			flags.Int64(name, value.Int(), usage)
+		case reflect.TypeOf(uint(0)):
+			flags.Uint(name, uint(value.Uint()), usage)
+		case reflect.TypeOf(uint64(0)):
+			flags.Uint64(name, value.Uint(), usage)
+		case reflect.TypeOf(float64(0)):
+			flags.Float64(name, value.Float(), usage)
+		case durationType:
+			flags.Duration(name, time.Duration(value.Int()), usage)
+		default:
+			flags.Var(&fieldFlag{value: value, tag: field.Tag}, name, usage)
+		}
+		return false
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// LoadFlags sets the fields of the struct v points to from the flags of
+// flags that were given on the command line, leaving the fields of the
+// flags that were not given untouched. flags must be parsed first.
+//
+// Flags defined by RegisterFlags set the field they were defined for. Any
+// other flag sets the field it names, as an option does in LoadOptions.
+func LoadFlags(flags *flag.FlagSet, v any, opts ...LoadOption) error {
+	elem, err := structElem(v)
+	if err != nil {
+		return err
+	}
+	st := newLoadState(append(opts[:len(opts):len(opts)], fromFlags()))
+	visited := map[string]*flag.Flag{}
+	flags.Visit(func(f *flag.Flag) {
+		visited[f.Name] = f
+	})
+	walkFlags(new(walkStack), elem, "", "", func(name, path string, field reflect.StructField, fieldValue reflect.Value) bool {
+		f, ok := visited[name]
+		if !ok {
+			return false
+		}
+		delete(visited, name)
+		if err := setOptionField(fieldValue, flagValue(f), false); err != nil {
+			st.addError(&FieldError{Path: path, Source: SourceFlag, Key: name, Err: err})
+			return false
+		}
+		st.record(fieldValue, field, Origin{Path: path, Source: SourceFlag, Key: name})
+		return true
+	})
+	if len(visited) > 0 {
+		options := Options{}
+		for name, f := range visited {
+			options[name] = flagValue(f)
+		}
+		st.loadOptions(elem, options)
+	}
+	return st.err()
+}
+
+// walkFlags calls fn for every field of elem with a flag tag. prefix is
+// prepended to the flag names and path is the field path of elem. stack
+// holds the structs enclosing elem. fn reports whether it set the field,
+// and walkFlags whether any was set.
+func walkFlags(stack *walkStack, elem reflect.Value, prefix, path string, fn func(name, path string, field reflect.StructField, fieldValue reflect.Value) bool) bool {
+	defer stack.push(elem)()
+	set := false
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := elem.Field(i)
+		fieldPath := joinPath(path, field.Name)
+		name, _ := parseTag(field.Tag.Get("flag"))
+		if name == "-" {
+			continue
+		}
+		if isNestedStruct(field.Type) {
+			nestedPrefix := prefix
+			if name != "" {
+				nestedPrefix += name + "."
+			}
+			set = stack.descend(fieldValue, func(nested reflect.Value) bool {
+				return walkFlags(stack, nested, nestedPrefix, fieldPath, fn)
+			}) || set
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		set = fn(prefix+name, fieldPath, field, fieldValue) || set
+	}
+	return set
+}
+
+// flagValue returns the parsed value of f.
+func flagValue(f *flag.Flag) any {
+	if getter, ok := f.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return f.Value.String()
+}
+
+// isSecretField reports whether field must not reveal its value.
+func isSecretField(field reflect.StructField) bool {
+	_, envOpts := parseTag(field.Tag.Get("env"))
+	return field.Tag.Get("secret") == "true" || envOpts.contains("secret")
+}
+
+// fieldFlag is the flag.Value of a field whose type has no flag of its
+// own. It parses its value as LoadEnv does.
+type fieldFlag struct {
+	value  reflect.Value
+	tag    reflect.StructTag
+	secret bool
+}
+
+func (f *fieldFlag) String() string {
+	// The flag package calls String on a zero fieldFlag to find out
+	// whether a default is worth showing.
+	if !f.value.IsValid() || f.value.IsZero() {
+		return ""
+	}
+	if f.secret {
+		return redacted
+	}
+	s, _ := formatValue(f.value)
+	return s
+}
+
+func (f *fieldFlag) Set(s string) error {
+	value := reflect.New(f.value.Type()).Elem()
+	if err := setEnvValue(value, s, f.tag); err != nil {
+		return err
+	}
+	f.value.Set(value)
+	return nil
+}
+
+func (f *fieldFlag) Get() any {
+	return f.value.Interface()
+}
+
+// IsBoolFlag lets boolean fields, including NullBool, be given as -name
+// without a value.
+func (f *fieldFlag) IsBoolFlag() bool {
+	if !f.value.IsValid() {
+		return false
+	}
+	t := f.value.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isNullType(t) {
+		t = t.Field(0).Type
+	}
+	return t.Kind() == reflect.Bool
+}
@@ -0,0 +1,201 @@
+package gottings
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlags(t *testing.T) {
+	type Database struct {
+		Host string `flag:"host" usage:"database host"`
+		Port int8   `flag:"port"`
+	}
+	type Config struct {
+		Host     string        `json:"host" env:"TEST_HOST" flag:"host" usage:"host to listen on"`
+		Port     int           `json:"port" env:"TEST_PORT" flag:"port" usage:"port to listen on" default:"8000"`
+		Debug    NullBool      `flag:"debug"`
+		Verbose  bool          `flag:"verbose"`
+		Timeout  time.Duration `flag:"timeout" default:"30s"`
+		Tags     []string      `flag:"tags"`
+		Password string        `env:"TEST_PASSWORD,secret" flag:"password"`
+		Token    Secret        `flag:"token"`
+		Name     string
+		Database *Database `flag:"db"`
+	}
+
+	t.Run("defaults show the loaded values", func(t *testing.T) {
+		config := Config{Host: "example.com", Password: "hunter2", Token: NewSecret("t0ken")}
+		flags, err := NewFlagSet("test", &config, flag.ContinueOnError)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var usage bytes.Buffer
+		flags.SetOutput(&usage)
+		flags.PrintDefaults()
+		expected := []string{
+			"-host string\n    \thost to listen on (default \"example.com\")",
+			"-port int\n    \tport to listen on (default 8000)",
+			"-timeout duration\n    \t (default 30s)",
+			"-debug\n",
+			"-db.host string\n    \tdatabase host",
+			"-db.port value",
+			"-password value\n    \t (default ******)",
+		}
+		for _, line := range expected {
+			if !strings.Contains(usage.String(), line) {
+				t.Errorf("expected %q in usage:\n%s", line, usage.String())
+			}
+		}
+		for _, secret := range []string{"hunter2", "t0ken"} {
+			if strings.Contains(usage.String(), secret) {
+				t.Errorf("secret %s leaked in usage:\n%s", secret, usage.String())
+			}
+		}
+		if flags.Lookup("Name") != nil || flags.Lookup("name") != nil {
+			t.Errorf("expected no flag for untagged fields")
+		}
+		if config.Port != 0 || config.Timeout != 0 {
+			t.Errorf("expected RegisterFlags not to modify the config, got %+v", config)
+		}
+	})
+	t.Run("only given flags are applied", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "9000")
+		config := Config{}
+		if err := LoadConfiguration([]byte(`{"host": "example.com"}`), &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		flags, err := NewFlagSet("test", &config, flag.ContinueOnError)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		args := []string{"-debug", "-timeout", "1m", "-tags", "a,b", "-token", "t0ken", "-db.port", "5"}
+		if err := flags.Parse(args); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var report Report
+		if err := LoadFlags(flags, &config, WithReport(&report)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Config{
+			Host:     "example.com",
+			Port:     9000,
+			Debug:    NewNullBool(true),
+			Timeout:  time.Minute,
+			Tags:     []string{"a", "b"},
+			Token:    config.Token,
+			Database: &Database{Port: 5},
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+		if config.Token.Reveal() != "t0ken" {
+			t.Errorf("Expected t0ken got %q", config.Token.Reveal())
+		}
+		origin, _ := report.Lookup("Database.Port")
+		if origin.Source != SourceFlag || origin.Key != "db.port" {
+			t.Errorf("unexpected origin %+v", origin)
+		}
+	})
+	t.Run("flags without tag", func(t *testing.T) {
+		config := Config{}
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags.String("Name", "", "")
+		flags.String("Host", "", "")
+		if err := flags.Parse([]string{"-Name", "app"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := LoadFlags(flags, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Name != "app" || config.Host != "" {
+			t.Errorf("unexpected result %+v", config)
+		}
+	})
+	t.Run("unsigned and complex flags", func(t *testing.T) {
+		type Numbers struct {
+			Port    uint       `flag:"port" default:"8080"`
+			Size    uint64     `flag:"size"`
+			Mask    uint8      `flag:"mask"`
+			Workers NullUint16 `flag:"workers"`
+			Phase   complex128 `flag:"phase"`
+		}
+		config := Numbers{}
+		flags, err := NewFlagSet("test", &config, flag.ContinueOnError)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var usage bytes.Buffer
+		flags.SetOutput(&usage)
+		flags.PrintDefaults()
+		if !strings.Contains(usage.String(), "-port uint\n    \t (default 8080)") {
+			t.Errorf("expected a uint flag for Port in usage:\n%s", usage.String())
+		}
+		args := []string{"-size", "18446744073709551615", "-mask", "255", "-workers", "4", "-phase", "1+2i"}
+		if err := flags.Parse(args); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := LoadFlags(flags, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Numbers{Size: math.MaxUint64, Mask: 255, Workers: NewNullUint16(4), Phase: complex(1, 2)}
+		if config != expected {
+			t.Errorf("result %+v does not match expected %+v", config, expected)
+		}
+		if err := flags.Parse([]string{"-mask", "256"}); err == nil {
+			t.Errorf("expected a parse error for an out of range value")
+		}
+	})
+	t.Run("self-referential struct", func(t *testing.T) {
+		type Node struct {
+			Name string `flag:"name"`
+			Next *Node  `flag:"next"`
+		}
+		node := Node{}
+		flags, err := NewFlagSet("test", &node, flag.ContinueOnError)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := flags.Parse([]string{"-name", "x"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := LoadFlags(flags, &node); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if node.Name != "x" || node.Next != nil || flags.Lookup("next.name") != nil {
+			t.Errorf("unexpected result %+v", node)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		config := Config{}
+		flags, err := NewFlagSet("test", &config, flag.ContinueOnError)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		flags.SetOutput(&bytes.Buffer{})
+		if err := flags.Parse([]string{"-db.port", "1000"}); err == nil {
+			t.Errorf("expected a parse error for an out of range value")
+		}
+
+		type Duplicate struct {
+			A string `flag:"name"`
+			B string `flag:"name"`
+		}
+		if _, err := NewFlagSet("test", &Duplicate{}, flag.ContinueOnError); err == nil {
+			t.Errorf("expected an error for a redefined flag")
+		}
+		type BadDefault struct {
+			Port int `flag:"port" default:"http"`
+		}
+		_, err = NewFlagSet("test", &BadDefault{}, flag.ContinueOnError)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Path != "Port" {
+			t.Errorf("expected *FieldError for Port, got %v", err)
+		}
+	})
+}
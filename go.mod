@@ -2,3 +2,7 @@ module github.com/sondalex/gottings
 
 go 1.21.3
 
+require (
+	github.com/BurntSushi/toml v1.5.0
+	gopkg.in/yaml.v3 v3.0.1
+)
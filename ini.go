@@ -0,0 +1,87 @@
+package gottings
+
+import (
+	"reflect"
+	"strings"
+)
+
+var iniDecoder = stringTreeDecoder("ini", SourceINI)
+
+// LoadINIConfiguration is like LoadConfiguration but reads an INI document:
+//
+//	; comments start with ; or #
+//	host = 127.0.0.1
+//
+//	[database]
+//	host = db.internal
+//	port: 5432
+//
+//	[database.replica]
+//	hosts = "a,b"
+//
+// Keys before the first section belong to the top-level struct, and each
+// section, as well as each dotted key, selects a nested struct field. Keys
+// are matched against the ini tag of each field, then its json tag, then
+// its name, case-insensitively. Surrounding quotes are stripped from values,
+// which are then converted as LoadEnv converts environment values, including
+// the envSeparator and envKeyValSeparator tags. A section can also fill a map
+// field.
+//
+// Field errors report the line and column of the offending value.
+func LoadINIConfiguration(data []byte, v any, opts ...LoadOption) error {
+	return loadConfiguration(data, v, opts, (*loadState).loadINIDocument)
+}
+
+// loadINIDocument decodes the INI document data into elem.
+func (st *loadState) loadINIDocument(elem reflect.Value, data []byte) error {
+	table, err := parseINI(string(data))
+	if err != nil {
+		return err
+	}
+	iniDecoder.decode(st, elem, table, "", "")
+	return nil
+}
+
+// parseINI parses src into a tree of tables whose leaves are *stringValue.
+func parseINI(src string) (map[string]any, error) {
+	root := map[string]any{}
+	section := ""
+	for i, line := range strings.Split(src, "\n") {
+		lineNumber := i + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#' {
+			continue
+		}
+		if trimmed[0] == '[' {
+			if !strings.HasSuffix(trimmed, "]") {
+				return nil, &SyntaxError{Line: lineNumber, Msg: "expected ']' at end of section"}
+			}
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if section == "" {
+				return nil, &SyntaxError{Line: lineNumber, Msg: "empty section name"}
+			}
+			continue
+		}
+		sep := strings.IndexAny(line, "=:")
+		if sep < 0 {
+			return nil, &SyntaxError{Line: lineNumber, Msg: "expected '=' or ':' after key"}
+		}
+		key := strings.TrimSpace(line[:sep])
+		if key == "" {
+			return nil, &SyntaxError{Line: lineNumber, Msg: "expected key before separator"}
+		}
+		rest := line[sep+1:]
+		value := strings.TrimSpace(rest)
+		column := sep + 2 + strings.Index(rest, value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		if err := insertTreeValue(root, key, &stringValue{raw: value, line: lineNumber, column: column}); err != nil {
+			return nil, &SyntaxError{Line: lineNumber, Msg: err.Error()}
+		}
+	}
+	return root, nil
+}
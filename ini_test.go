@@ -0,0 +1,157 @@
+package gottings
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadINIConfiguration(t *testing.T) {
+	type Replica struct {
+		Hosts []string `ini:"hosts"`
+	}
+	type Database struct {
+		Host    string   `ini:"host"`
+		Port    NullInt  `json:"port"`
+		Replica *Replica `ini:"replica"`
+	}
+	type Config struct {
+		Host     string              `ini:"host" env:"TEST_HOST"`
+		Port     int                 `json:"port" env:"TEST_PORT"`
+		Name     string              `ini:"name"`
+		Timeout  Null[time.Duration] `ini:"timeout"`
+		Debug    *bool               `ini:"debug"`
+		Labels   map[string]string   `ini:"labels"`
+		Database Database            `ini:"database"`
+	}
+
+	t.Run("load from file", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "8080")
+		data, err := os.ReadFile("testdata/info.ini")
+		if err != nil {
+			panic(err)
+		}
+		config := Config{}
+		if err := LoadINIConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if config.Port != 8080 {
+			t.Errorf("Expected 8080 got config.Port=%d\n", config.Port)
+		}
+		if config.Host != "127.0.0.1" {
+			t.Errorf("Expected 127.0.0.1 got config.Host=%s\n", config.Host)
+		}
+	})
+	t.Run("sections and dotted keys", func(t *testing.T) {
+		data := []byte(`
+# comment
+host = example.com
+name = "quoted value"
+timeout: 1m30s
+debug = true
+
+[labels]
+team = core
+
+[database]
+host = db
+port = 5432
+
+[database.replica]
+hosts = a,b
+`)
+		config := Config{}
+		if err := LoadINIConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		debug := true
+		expected := Config{
+			Host:    "example.com",
+			Name:    "quoted value",
+			Timeout: NewNull(90 * time.Second),
+			Debug:   &debug,
+			Labels:  map[string]string{"team": "core"},
+			Database: Database{
+				Host:    "db",
+				Port:    NewNullInt(5432),
+				Replica: &Replica{Hosts: []string{"a", "b"}},
+			},
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+
+		dotted := Config{}
+		if err := LoadINIConfiguration([]byte("database.host = db\n"), &dotted); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if dotted.Database.Host != "db" {
+			t.Errorf("Expected db got config.Database.Host=%s\n", dotted.Database.Host)
+		}
+	})
+	t.Run("field errors", func(t *testing.T) {
+		data := []byte("port = http\n[database]\nport =   x\n")
+		err := LoadINIConfiguration(data, &Config{})
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", err)
+		}
+		expected := []FieldError{
+			{Path: "Port", Source: SourceINI, Key: "port", Line: 1, Column: 8},
+			{Path: "Database.Port", Source: SourceINI, Key: "database.port", Line: 3, Column: 10},
+		}
+		for i, err := range errs {
+			fieldErr := err.(*FieldError)
+			got := FieldError{Path: fieldErr.Path, Source: fieldErr.Source, Key: fieldErr.Key, Line: fieldErr.Line, Column: fieldErr.Column}
+			if got != expected[i] {
+				t.Errorf("expected %+v, got %+v", expected[i], got)
+			}
+		}
+	})
+	t.Run("embedded pointer to unexported struct", func(t *testing.T) {
+		type inner struct {
+			Host string `ini:"host"`
+		}
+		type Config struct {
+			*inner
+			Port int `ini:"port"`
+		}
+		config := Config{}
+		err := LoadINIConfiguration([]byte("host = x\nport = 1\n"), &config)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Path != "inner" || fieldErr.Source != SourceINI {
+			t.Fatalf("expected *FieldError for inner, got %v", err)
+		}
+		if config.Port != 1 || config.inner != nil {
+			t.Errorf("unexpected result %+v", config)
+		}
+		if err := LoadINIConfiguration([]byte("port = 1\n"), &Config{}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+	t.Run("syntax errors", func(t *testing.T) {
+		testCases := []struct {
+			name string
+			data string
+			line int
+		}{
+			{name: "unterminated section", data: "host = a\n[database\n", line: 2},
+			{name: "missing separator", data: "host\n", line: 1},
+			{name: "value and section", data: "database = a\n[database]\nhost = b\n", line: 3},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := LoadINIConfiguration([]byte(tc.data), &Config{})
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("expected *SyntaxError, got %v", err)
+				}
+				if syntaxErr.Line != tc.line {
+					t.Errorf("expected line %d, got %d", tc.line, syntaxErr.Line)
+				}
+			})
+		}
+	})
+}
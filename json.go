@@ -0,0 +1,330 @@
+package gottings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// loadJSONDocument decodes the JSON document data into elem. A document
+// that is neither an object nor null is reported as encoding/json reports
+// it.
+func (st *loadState) loadJSONDocument(elem reflect.Value, data []byte) error {
+	if reflect.PointerTo(elem.Type()).Implements(jsonUnmarshalerType) {
+		return json.Unmarshal(data, elem.Addr().Interface())
+	}
+	if !json.Valid(data) {
+		// Report the syntax error with its offset in data.
+		return json.Unmarshal(data, &struct{}{})
+	}
+	object, err := decodeJSONObject(data, elem.Type())
+	if err != nil {
+		return err
+	}
+	st.loadJSON(elem, object, "", "")
+	return nil
+}
+
+// loadJSON decodes the members of a JSON object into elem one field at a
+// time, so that a failure can be attributed to its field and does not
+// prevent the remaining fields from loading. Fields are those encoding/json
+// decodes, see jsonFields, and each key goes to the field of the same name,
+// or failing that to one whose name matches case-insensitively, the last
+// matching key of the document winning. The string tag option is honored
+// as well. path is the field path of elem and keyPath its JSON key path. It
+// reports whether any field was set.
+func (st *loadState) loadJSON(elem reflect.Value, object []jsonMember, path, keyPath string) bool {
+	fields := jsonFields(elem.Type())
+	members := make([]*jsonMember, len(fields))
+	for i := range object {
+		if f := lookupJSONField(fields, object[i].key); f >= 0 {
+			members[f] = &object[i]
+		}
+	}
+	set := false
+	unsettable := map[string]bool{}
+	for f, field := range fields {
+		member := members[f]
+		if member == nil {
+			continue
+		}
+		fieldValue, embedded, ok := jsonFieldValue(elem, field.index)
+		if !ok {
+			if !unsettable[embedded] {
+				unsettable[embedded] = true
+				err := fmt.Errorf("cannot set embedded pointer to unexported struct: %v", fieldValue.Type().Elem())
+				st.addError(&FieldError{Path: joinPath(path, embedded), Source: SourceJSON, Key: keyPath, Err: err})
+			}
+			continue
+		}
+		fieldPath := joinPath(path, field.field.Name)
+		fieldKeyPath := joinPath(keyPath, member.key)
+		if isJSONObject(fieldValue.Type()) && !bytes.Equal(member.raw, []byte("null")) {
+			nested, err := decodeJSONObject(member.raw, fieldValue.Type())
+			if err != nil {
+				st.addError(&FieldError{Path: fieldPath, Source: SourceJSON, Key: fieldKeyPath, Err: err})
+				continue
+			}
+			set = descend(fieldValue, func(elem reflect.Value) bool {
+				return st.loadJSON(elem, nested, fieldPath, fieldKeyPath)
+			}) || set
+			continue
+		}
+		decode := unmarshalJSONValue
+		if field.quoted {
+			decode = unmarshalJSONString
+		}
+		if err := decode(fieldValue, member.raw); err != nil {
+			st.addError(&FieldError{Path: fieldPath, Source: SourceJSON, Key: fieldKeyPath, Err: err})
+			continue
+		}
+		st.record(fieldValue, field.field, Origin{Path: fieldPath, Source: SourceJSON, Key: fieldKeyPath, File: st.file})
+		set = true
+	}
+	return set
+}
+
+// unmarshalJSONValue decodes raw into target as encoding/json does, except
+// that complex numbers, which encoding/json cannot decode, are read from a
+// JSON number or from a string such as "1+2i".
+func unmarshalJSONValue(target reflect.Value, raw json.RawMessage) error {
+	t := target.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !isComplexKind(t.Kind()) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return json.Unmarshal(raw, target.Addr().Interface())
+	}
+	if bytes.Equal(raw, []byte("null")) {
+		if target.Kind() == reflect.Pointer {
+			target.Set(reflect.Zero(target.Type()))
+		}
+		return nil
+	}
+	s := string(raw)
+	if len(raw) > 0 && raw[0] == '"' {
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+	}
+	if target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(t))
+		}
+		target = target.Elem()
+	}
+	return setScalar(target, s)
+}
+
+// unmarshalJSONString decodes raw into target as encoding/json decodes a
+// field with the string option, such as `json:"port,string"`, from a JSON
+// string holding the value.
+func unmarshalJSONString(target reflect.Value, raw json.RawMessage) error {
+	wrapper := reflect.New(reflect.StructOf([]reflect.StructField{
+		{Name: "V", Type: target.Type(), Tag: `json:"v,string"`},
+	})).Elem()
+	wrapper.Field(0).Set(target)
+	data := append(append([]byte(`{"v":`), raw...), '}')
+	if err := json.Unmarshal(data, wrapper.Addr().Interface()); err != nil {
+		return err
+	}
+	target.Set(wrapper.Field(0))
+	return nil
+}
+
+// isJSONObject reports whether t, or the type t points to, is a struct that
+// loadJSON walks rather than handing over to encoding/json.
+func isJSONObject(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return isNestedStruct(t) && !reflect.PointerTo(t).Implements(jsonUnmarshalerType)
+}
+
+// jsonField is a field that encoding/json decodes, possibly promoted from
+// an embedded struct.
+type jsonField struct {
+	name   string
+	field  reflect.StructField
+	tagged bool
+	quoted bool
+	index  []int
+}
+
+// jsonFields returns the fields of the struct t that encoding/json decodes,
+// in the order of their index. The fields of embedded structs are promoted,
+// and among the fields sharing a name the dominant one is kept, as in
+// encoding/json: the shallowest, then the one with a json tag name. When
+// that leaves several fields, none of them is decoded.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	current, next := []embedded{}, []embedded{{t: t}}
+	count, nextCount := map[reflect.Type]int{}, map[reflect.Type]int{t: 1}
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+		for _, e := range current {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for i := 0; i < e.t.NumField(); i++ {
+				field := e.t.Field(i)
+				name, opts := parseTag(field.Tag.Get("json"))
+				if name == "-" {
+					continue
+				}
+				index := append(append([]int(nil), e.index...), i)
+				if field.Anonymous && name == "" && isJSONObject(field.Type) {
+					ft := field.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					nextCount[ft]++
+					if nextCount[ft] == 1 {
+						next = append(next, embedded{t: ft, index: index})
+					}
+					continue
+				}
+				if !field.IsExported() {
+					continue
+				}
+				f := jsonField{name: name, field: field, tagged: name != "", quoted: opts.contains("string"), index: index}
+				if f.name == "" {
+					f.name = field.Name
+				}
+				fields = append(fields, f)
+				if count[e.t] > 1 {
+					// The struct is embedded twice at this depth, so its
+					// fields annihilate each other.
+					fields = append(fields, f)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+		return a.tagged && !b.tagged
+	})
+	dominant := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		if j-i == 1 || len(fields[i+1].index) > len(fields[i].index) || fields[i].tagged != fields[i+1].tagged {
+			dominant = append(dominant, fields[i])
+		}
+		i = j
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		a, b := dominant[i].index, dominant[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return dominant
+}
+
+// lookupJSONField returns the index in fields of the field the key decodes
+// into, preferring an exact match over a case-insensitive one, or -1.
+func lookupJSONField(fields []jsonField, key string) int {
+	fold := -1
+	for i, field := range fields {
+		if field.name == key {
+			return i
+		}
+		if fold < 0 && strings.EqualFold(field.name, key) {
+			fold = i
+		}
+	}
+	return fold
+}
+
+// jsonFieldValue returns the field of elem at index, allocating the
+// embedded pointers leading to it. A nil pointer to an unexported struct
+// cannot be allocated; it is returned with its field path and false.
+func jsonFieldValue(elem reflect.Value, index []int) (reflect.Value, string, bool) {
+	var path string
+	v := elem
+	for k, i := range index {
+		if k > 0 {
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					if !v.CanSet() {
+						return v, path, false
+					}
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+		}
+		path = joinPath(path, v.Type().Field(i).Name)
+		v = v.Field(i)
+	}
+	return v, path, true
+}
+
+// jsonMember is a key of a JSON object and its value.
+type jsonMember struct {
+	key string
+	raw json.RawMessage
+}
+
+// decodeJSONObject returns the members of the JSON object data in document
+// order. A null document has no members. Anything else is reported as
+// encoding/json reports it when decoding into t.
+func decodeJSONObject(data []byte, t reflect.Type) ([]jsonMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case nil:
+		return nil, nil
+	case json.Delim:
+		if token != '{' {
+			return nil, &json.UnmarshalTypeError{Value: "array", Type: t, Offset: dec.InputOffset()}
+		}
+	case string:
+		return nil, &json.UnmarshalTypeError{Value: "string", Type: t, Offset: dec.InputOffset()}
+	case bool:
+		return nil, &json.UnmarshalTypeError{Value: "bool", Type: t, Offset: dec.InputOffset()}
+	default:
+		return nil, &json.UnmarshalTypeError{Value: "number", Type: t, Offset: dec.InputOffset()}
+	}
+	var members []jsonMember
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		members = append(members, jsonMember{key: key.(string), raw: raw})
+	}
+	return members, nil
+}
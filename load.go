@@ -0,0 +1,284 @@
+package gottings
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+)
+
+var errUnexported = errors.New("cannot set unexported field")
+
+// loadState carries what a single LoadEnv, LoadOptions or LoadConfiguration
+// call has done so far.
+type loadState struct {
+	// set holds the path of every field assigned by the current call.
+	set map[string]bool
+	// errs collects field errors so that one call reports all of them.
+	errs Errors
+
+	// lookup reads environment variables, and envSource is reported as
+	// the source of the values it returns.
+	lookup    func(key string) (string, bool)
+	envSource Source
+
+	// optionSource is reported as the source of option values.
+	optionSource Source
+
+	// allowEmpty makes environment variables that are set to an empty
+	// string count as set.
+	allowEmpty bool
+	// setenv makes LoadDotEnv export the variables it reads.
+	setenv bool
+	// aliasPointers makes pointer options of the type of their field be
+	// stored as is rather than copied.
+	aliasPointers bool
+	// normalizeKeys makes option keys match regardless of case, dashes
+	// and underscores.
+	normalizeKeys bool
+	// envFiles makes every field read its value from the file named by
+	// the <KEY>_FILE variable when <KEY> is unset.
+	envFiles bool
+	// layered is set when the call loads a single layer of a Loader. The
+	// Loader then applies defaults, the environment and the required
+	// check as layers of their own. layer is the name of that layer.
+	layered bool
+	layer   string
+
+	// stack holds the structs being walked by the current call.
+	stack walkStack
+
+	// report receives the origin of every field set, when requested.
+	report *Report
+	// file is the path of the file being decoded, when known.
+	file string
+}
+
+// LoadOption configures a single call to one of the Load functions.
+type LoadOption func(*loadState)
+
+// AllowEmpty makes environment variables that are set but empty override
+// the field, as os.LookupEnv would report them, instead of being treated as
+// unset. It can also be enabled for a single field with the allowempty
+// option of the env tag:
+//
+//	type Config struct {
+//	     Host NullString `env:"APP_HOST,allowempty"`
+//	}
+func AllowEmpty() LoadOption {
+	return func(st *loadState) {
+		st.allowEmpty = true
+	}
+}
+
+// inLoader makes a call load the layer named name of a Loader, recording
+// the fields it sets in set.
+func inLoader(set map[string]bool, name string) LoadOption {
+	return func(st *loadState) {
+		st.layered = true
+		st.set = set
+		st.layer = name
+	}
+}
+
+// fromFlags reports option values as coming from command line flags.
+func fromFlags() LoadOption {
+	return func(st *loadState) {
+		st.optionSource = SourceFlag
+	}
+}
+
+// EnvFiles makes every field whose environment variable is unset read its
+// value from the file named by the same variable with a _FILE suffix, as
+// Docker and Kubernetes secrets are usually passed:
+//
+//	DB_PASSWORD_FILE=/run/secrets/db
+//
+// The trailing newline of the file is removed and its content is converted
+// like the value of the variable. It can also be enabled for a single field
+// with the file option of the env tag:
+//
+//	type Config struct {
+//	     Password Secret `env:"DB_PASSWORD,file"`
+//	}
+func EnvFiles() LoadOption {
+	return func(st *loadState) {
+		st.envFiles = true
+	}
+}
+
+// AliasPointers makes LoadOptions store a pointer option in a pointer
+// field of the same type as is, so that the field and the option share
+// their value. Without it the value the option points to is copied:
+//
+//	port := flag.Int("port", 1312, "")
+//	LoadOptions(Options{"Port": port}, config, AliasPointers()) // config.Port == port
+func AliasPointers() LoadOption {
+	return func(st *loadState) {
+		st.aliasPointers = true
+	}
+}
+
+// NormalizeKeys makes LoadOptions and LoadFlags match option keys
+// case-insensitively, ignoring dashes and underscores, so that "max-conns",
+// "max_conns" and "MaxConns" all name the same field.
+func NormalizeKeys() LoadOption {
+	return func(st *loadState) {
+		st.normalizeKeys = true
+	}
+}
+
+func newLoadState(opts []LoadOption) *loadState {
+	st := &loadState{set: map[string]bool{}, lookup: os.LookupEnv, envSource: SourceEnv, optionSource: SourceOption}
+	for _, opt := range opts {
+		opt(st)
+	}
+	return st
+}
+
+func (st *loadState) addError(err error) {
+	st.errs = append(st.errs, err)
+}
+
+// addFieldError records fieldErr. When its Err lists field errors of its
+// own, as those of a struct decoded inside a list, they already carry the
+// path of the offending field and are recorded in its place.
+func (st *loadState) addFieldError(fieldErr *FieldError) {
+	if errs, ok := fieldErr.Err.(Errors); ok {
+		st.errs = append(st.errs, errs...)
+		return
+	}
+	st.addError(fieldErr)
+}
+
+// err returns the collected errors, or nil when there are none.
+func (st *loadState) err() error {
+	if len(st.errs) == 0 {
+		return nil
+	}
+	return st.errs
+}
+
+// checkRequired records a MissingError for every required field of elem
+// that was not set by the current call and still holds its zero value, or
+// that holds no value at all, such as a nil pointer or a Null value that is
+// not Valid, even when a source set it to null. A required nested struct is
+// missing when it is a nil pointer, or when it holds its zero value and
+// none of its fields was set.
+func (st *loadState) checkRequired(elem reflect.Value) {
+	var missing []string
+	st.collectMissing(elem, "", "", &missing)
+	if len(missing) > 0 {
+		st.addError(&MissingError{Keys: missing})
+	}
+}
+
+func (st *loadState) collectMissing(elem reflect.Value, prefix, path string, missing *[]string) {
+	defer st.stack.push(elem)()
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := elem.Field(i)
+		fieldPath := joinPath(path, field.Name)
+		envKey, opts := parseTag(field.Tag.Get("env"))
+		required := opts.contains("required") || field.Tag.Get("required") == "true"
+		if envKey == "" && isNestedStruct(field.Type) {
+			if st.stack.walking(fieldValue) {
+				continue
+			}
+			if required && st.missingStruct(fieldValue, fieldPath) {
+				*missing = append(*missing, fieldPath)
+				continue
+			}
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					fieldValue = reflect.New(fieldValue.Type().Elem())
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			st.collectMissing(fieldValue, prefix+field.Tag.Get("envPrefix"), fieldPath, missing)
+			continue
+		}
+		if !required {
+			continue
+		}
+		if !isUnsetValue(fieldValue) && (st.set[fieldPath] || !fieldValue.IsZero()) {
+			continue
+		}
+		if envKey != "" {
+			*missing = append(*missing, prefix+envKey)
+		} else {
+			*missing = append(*missing, fieldPath)
+		}
+	}
+}
+
+// missingStruct reports whether the nested struct fieldValue, found at
+// path, was left unconfigured.
+func (st *loadState) missingStruct(fieldValue reflect.Value, path string) bool {
+	if fieldValue.Kind() == reflect.Pointer {
+		return fieldValue.IsNil()
+	}
+	if !fieldValue.IsZero() {
+		return false
+	}
+	for setPath := range st.set {
+		if strings.HasPrefix(setPath, path+".") {
+			return false
+		}
+	}
+	return true
+}
+
+// isUnsetValue reports whether v holds no value at all: it is a nil
+// pointer, or a Null value that is not Valid.
+func isUnsetValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return isNullType(v.Type()) && !v.FieldByName("Valid").Bool()
+}
+
+// structElem returns the struct v points to.
+func structElem(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("expected pointer to struct")
+	}
+	return rv.Elem(), nil
+}
+
+// joinPath joins the path of a parent struct and the name of one of its
+// fields, e.g. "Database" and "Host" into "Database.Host".
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// tagOptions is the comma separated list following the name in a tag such
+// as `env:"APP_PORT,required"`.
+type tagOptions string
+
+// parseTag splits a tag into its name and its options.
+func parseTag(tag string) (string, tagOptions) {
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, tagOptions(opts)
+}
+
+func (o tagOptions) contains(option string) bool {
+	for o != "" {
+		name, rest, _ := strings.Cut(string(o), ",")
+		if name == option {
+			return true
+		}
+		o = tagOptions(rest)
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package gottings
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+)
+
+// Layer is a single source of configuration registered with a Loader.
+//
+// Load populates v from the source, passing opts on to the Load function
+// it calls. The Loader relies on these options to share what was set
+// between layers and to keep each layer from applying defaults, reading
+// the environment or checking required fields on its own.
+type Layer interface {
+	// Name identifies the layer, e.g. "env" or the path of a file.
+	Name() string
+	Load(v any, opts ...LoadOption) error
+}
+
+type layerFunc struct {
+	name string
+	load func(v any, opts ...LoadOption) error
+}
+
+func (l *layerFunc) Name() string {
+	return l.name
+}
+
+func (l *layerFunc) Load(v any, opts ...LoadOption) error {
+	return l.load(v, opts...)
+}
+
+// LayerFunc returns a Layer named name that loads with fn. fn must forward
+// opts to the Load function it calls, e.g. to load a document fetched from
+// a remote store:
+//
+//	remote := gottings.LayerFunc("consul", func(v any, opts ...gottings.LoadOption) error {
+//	     data, err := fetch()
+//	     if err != nil {
+//	          return err
+//	     }
+//	     return gottings.LoadYAMLConfiguration(data, v, opts...)
+//	})
+func LayerFunc(name string, fn func(v any, opts ...LoadOption) error) Layer {
+	return &layerFunc{name: name, load: fn}
+}
+
+// Loader loads a configuration from several layers. Layers run in the
+// order they were added and each one only assigns the fields its source
+// sets, so later layers take precedence over earlier ones:
+//
+//	loader := gottings.NewLoader(
+//	     gottings.DefaultsLayer(),
+//	     gottings.FileLayer("config.yaml"),
+//	     gottings.Optional(gottings.FileLayer("config.local.yaml")),
+//	     gottings.EnvLayer(),
+//	     gottings.FlagLayer(flag.CommandLine),
+//	)
+//	err := loader.Load(config)
+//
+// What a layer sets is fixed by its source:
+//
+//   - DefaultsLayer sets the fields with a default tag that no earlier
+//     layer set and that still hold their zero value.
+//   - File layers set the fields whose key appears in the document, even
+//     when its value is null or zero.
+//   - EnvLayer and DotEnvLayer set the fields whose variable is set and,
+//     unless AllowEmpty is given, not empty.
+//   - OptionsLayer sets the fields whose option is present.
+//   - FlagLayer sets the fields whose flag was given on the command line,
+//     so that the default value of a flag never overrides another layer.
+//
+// Required fields are checked once all layers have run.
+type Loader struct {
+	layers []Layer
+}
+
+// NewLoader returns a Loader running layers in order.
+func NewLoader(layers ...Layer) *Loader {
+	return &Loader{layers: layers}
+}
+
+// Add appends layers, which take precedence over the layers already added.
+func (l *Loader) Add(layers ...Layer) *Loader {
+	l.layers = append(l.layers, layers...)
+	return l
+}
+
+// Load populates v from every layer in order, then reports the required
+// fields that no layer set. opts are passed to every layer. Field errors
+// of all layers are returned together; any other error stops the Loader.
+func (l *Loader) Load(v any, opts ...LoadOption) error {
+	elem, err := structElem(v)
+	if err != nil {
+		return err
+	}
+	st := newLoadState(opts)
+	for _, layer := range l.layers {
+		layerOpts := append(opts[:len(opts):len(opts)], inLoader(st.set, layer.Name()))
+		err := layer.Load(v, layerOpts...)
+		if errs, ok := err.(Errors); ok {
+			st.errs = append(st.errs, errs...)
+		} else if err != nil {
+			return err
+		}
+	}
+	st.checkRequired(elem)
+	return st.err()
+}
+
+// DefaultsLayer returns a Layer filling fields from their default tag.
+func DefaultsLayer() Layer {
+	return LayerFunc("defaults", func(v any, opts ...LoadOption) error {
+		elem, err := structElem(v)
+		if err != nil {
+			return err
+		}
+		st := newLoadState(opts)
+		st.applyDefaults(elem, "")
+		return st.err()
+	})
+}
+
+// EnvLayer returns a Layer reading the process environment, as LoadEnv
+// does.
+func EnvLayer() Layer {
+	return LayerFunc("env", LoadEnv)
+}
+
+// DotEnvLayer returns a Layer reading the .env file at path, as LoadDotEnv
+// does.
+func DotEnvLayer(path string) Layer {
+	return LayerFunc(path, func(v any, opts ...LoadOption) error {
+		return LoadDotEnv(path, v, opts...)
+	})
+}
+
+// FileLayer returns a Layer reading the configuration file at path, as
+// LoadFile does.
+func FileLayer(path string) Layer {
+	return LayerFunc(path, func(v any, opts ...LoadOption) error {
+		return LoadFile(path, v, opts...)
+	})
+}
+
+// FileLayerFS returns a Layer reading the configuration file at path in
+// fsys, as LoadFileFS does.
+func FileLayerFS(fsys fs.FS, path string) Layer {
+	return LayerFunc(path, func(v any, opts ...LoadOption) error {
+		return LoadFileFS(fsys, path, v, opts...)
+	})
+}
+
+// OptionsLayer returns a Layer setting fields from options, as LoadOptions
+// does.
+func OptionsLayer(options Options) Layer {
+	return LayerFunc("options", func(v any, opts ...LoadOption) error {
+		return LoadOptions(options, v, opts...)
+	})
+}
+
+// FlagLayer returns a Layer setting fields from the flags of flags that
+// were given on the command line, as LoadFlags does. flags must be parsed
+// before the Loader runs.
+func FlagLayer(flags *flag.FlagSet) Layer {
+	return LayerFunc("flags", func(v any, opts ...LoadOption) error {
+		return LoadFlags(flags, v, opts...)
+	})
+}
+
+// Optional returns a Layer that behaves like layer but is skipped when its
+// file does not exist. Field errors of the layer are still reported, even
+// those caused by a missing file, such as a _FILE variable naming one.
+func Optional(layer Layer) Layer {
+	return LayerFunc(layer.Name(), func(v any, opts ...LoadOption) error {
+		err := layer.Load(v, opts...)
+		if _, ok := err.(Errors); !ok && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	})
+}
@@ -0,0 +1,153 @@
+package gottings
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoader(t *testing.T) {
+	type Database struct {
+		Host string `json:"host" env:"TEST_DB_HOST" default:"localhost"`
+		Port int    `json:"port" env:"TEST_DB_PORT" default:"5432"`
+	}
+	type Config struct {
+		Host     string   `json:"host" env:"TEST_HOST" default:"127.0.0.1"`
+		Port     int      `json:"port" env:"TEST_PORT" default:"8000"`
+		Debug    bool     `json:"debug" env:"TEST_DEBUG"`
+		Name     string   `json:"name" env:"TEST_NAME" required:"true"`
+		Database Database `json:"database"`
+	}
+	fsys := fstest.MapFS{
+		"config.json":       {Data: []byte(`{"host": "file.example.com", "port": 8080, "name": "app", "database": {"host": "db"}}`)},
+		"config.local.yaml": {Data: []byte("port: 8081\n")},
+	}
+
+	t.Run("later layers take precedence", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "9000")
+		t.Setenv("TEST_HOST", "")
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags.Int("Port", 1312, "")
+		flags.Bool("Debug", false, "")
+		flags.String("Host", "flag.example.com", "")
+		if err := flags.Parse([]string{"-Debug"}); err != nil {
+			panic(err)
+		}
+
+		config := Config{}
+		err := NewLoader(
+			DefaultsLayer(),
+			FileLayerFS(fsys, "config.json"),
+			FileLayerFS(fsys, "config.local.yaml"),
+			EnvLayer(),
+			FlagLayer(flags),
+		).Load(&config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Config{
+			Host:     "file.example.com",
+			Port:     9000,
+			Debug:    true,
+			Name:     "app",
+			Database: Database{Host: "db", Port: 5432},
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("order of registration", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "9000")
+		config := Config{}
+		err := NewLoader(EnvLayer()).
+			Add(OptionsLayer(Options{"Port": 1312}), FileLayerFS(fsys, "config.json")).
+			Load(&config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port != 8080 {
+			t.Errorf("Expected 8080 got config.Port=%d\n", config.Port)
+		}
+	})
+	t.Run("layers do not apply defaults or the environment", func(t *testing.T) {
+		t.Setenv("TEST_HOST", "env.example.com")
+		config := Config{}
+		err := NewLoader(FileLayerFS(fsys, "config.local.yaml")).Load(&config)
+		if !errors.Is(err, ErrMissing) {
+			t.Fatalf("expected ErrMissing, got %v", err)
+		}
+		expected := Config{Port: 8081}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("required fields set by any layer", func(t *testing.T) {
+		config := Config{}
+		err := NewLoader(
+			OptionsLayer(Options{"Name": "app"}),
+			DefaultsLayer(),
+		).Load(&config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Host != "127.0.0.1" || config.Name != "app" {
+			t.Errorf("unexpected result %+v", config)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "abc")
+		err := NewLoader(
+			LayerFunc("broken", func(v any, opts ...LoadOption) error {
+				return LoadConfiguration([]byte(`{"port": "http"}`), v, opts...)
+			}),
+			EnvLayer(),
+			Optional(FileLayerFS(fsys, "missing.json")),
+		).Load(&Config{})
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Fatalf("expected 3 errors, got %v", err)
+		}
+		sources := []Source{SourceJSON, SourceEnv}
+		for i, source := range sources {
+			var fieldErr *FieldError
+			if !errors.As(errs[i], &fieldErr) || fieldErr.Source != source {
+				t.Errorf("expected *FieldError from %s, got %v", source, errs[i])
+			}
+		}
+		if !errors.Is(errs[2], ErrMissing) {
+			t.Errorf("expected ErrMissing, got %v", errs[2])
+		}
+
+		err = NewLoader(FileLayerFS(fsys, "missing.json")).Load(&Config{})
+		if err == nil || errors.As(err, &errs) {
+			t.Errorf("expected a read error, got %v", err)
+		}
+	})
+	t.Run("optional layers report field errors", func(t *testing.T) {
+		type Config struct {
+			Password string `env:"DB_PASSWORD,file"`
+			Port     int    `env:"APP_PORT"`
+		}
+		path := filepath.Join(t.TempDir(), "opt.env")
+		if err := os.WriteFile(path, []byte("DB_PASSWORD_FILE=/nonexistent\nAPP_PORT=abc\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		err := NewLoader(Optional(DotEnvLayer(path))).Load(&Config{})
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", err)
+		}
+		if !errors.Is(errs[0], fs.ErrNotExist) {
+			t.Errorf("expected the missing secret file to be reported, got %v", errs[0])
+		}
+
+		if err := NewLoader(Optional(DotEnvLayer(path + ".missing"))).Load(&Config{}); err != nil {
+			t.Errorf("expected a missing optional file to be skipped, got %v", err)
+		}
+	})
+}
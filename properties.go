@@ -0,0 +1,148 @@
+package gottings
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var propertiesDecoder = stringTreeDecoder("properties", SourceProperties)
+
+// LoadPropertiesConfiguration is like LoadConfiguration but reads a Java
+// .properties document:
+//
+//	# comments start with # or !
+//	host = 127.0.0.1
+//	database.host: db.internal
+//	database.port 5432
+//	message = a long value \
+//	          continued on the next line
+//
+// Keys and values are separated by '=', ':' or whitespace, and the escape
+// sequences \t, \n, \r, \f and \uXXXX are recognised. Each dotted key
+// selects a nested struct field. Keys are matched against the properties
+// tag of each field, then its json tag, then its name, case-insensitively.
+// Values are converted as LoadEnv converts environment values, including the
+// envSeparator and envKeyValSeparator tags.
+//
+// Field errors report the line and column of the offending value.
+func LoadPropertiesConfiguration(data []byte, v any, opts ...LoadOption) error {
+	return loadConfiguration(data, v, opts, (*loadState).loadPropertiesDocument)
+}
+
+// loadPropertiesDocument decodes the .properties document data into elem.
+func (st *loadState) loadPropertiesDocument(elem reflect.Value, data []byte) error {
+	table, err := parseProperties(string(data))
+	if err != nil {
+		return err
+	}
+	propertiesDecoder.decode(st, elem, table, "", "")
+	return nil
+}
+
+// parseProperties parses src into a tree of tables whose leaves are
+// *stringValue.
+func parseProperties(src string) (map[string]any, error) {
+	root := map[string]any{}
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSuffix(lines[i], "\r")
+		indent := len(line) - len(strings.TrimLeft(line, " \t\f"))
+		line = line[indent:]
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		for endsWithContinuation(line) && i+1 < len(lines) {
+			i++
+			next := strings.TrimSuffix(lines[i], "\r")
+			line = line[:len(line)-1] + strings.TrimLeft(next, " \t\f")
+		}
+		if endsWithContinuation(line) {
+			line = line[:len(line)-1]
+		}
+
+		keyEnd := 0
+		for keyEnd < len(line) && !strings.ContainsRune("=: \t\f", rune(line[keyEnd])) {
+			if line[keyEnd] == '\\' {
+				keyEnd++
+			}
+			keyEnd++
+		}
+		if keyEnd > len(line) {
+			keyEnd = len(line)
+		}
+		valueStart := keyEnd
+		for valueStart < len(line) && strings.ContainsRune(" \t\f", rune(line[valueStart])) {
+			valueStart++
+		}
+		if valueStart < len(line) && (line[valueStart] == '=' || line[valueStart] == ':') {
+			valueStart++
+			for valueStart < len(line) && strings.ContainsRune(" \t\f", rune(line[valueStart])) {
+				valueStart++
+			}
+		}
+
+		key, err := unescapeProperties(line[:keyEnd])
+		if err != nil {
+			return nil, &SyntaxError{Line: lineNumber, Msg: err.Error()}
+		}
+		value, err := unescapeProperties(line[valueStart:])
+		if err != nil {
+			return nil, &SyntaxError{Line: lineNumber, Msg: err.Error()}
+		}
+		str := &stringValue{raw: value, line: lineNumber, column: indent + valueStart + 1}
+		if err := insertTreeValue(root, key, str); err != nil {
+			return nil, &SyntaxError{Line: lineNumber, Msg: err.Error()}
+		}
+	}
+	return root, nil
+}
+
+// endsWithContinuation reports whether line ends with an odd number of
+// backslashes, i.e. continues on the next line.
+func endsWithContinuation(line string) bool {
+	n := 0
+	for n < len(line) && line[len(line)-1-n] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
+
+func unescapeProperties(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape %q", s[i-1:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape %q", s[i-1:i+5])
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,86 @@
+package gottings
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLoadPropertiesConfiguration(t *testing.T) {
+	type Database struct {
+		Host string  `properties:"host"`
+		Port NullInt `json:"port"`
+	}
+	type Config struct {
+		Host     string    `properties:"host" env:"TEST_HOST"`
+		Port     int       `json:"port" env:"TEST_PORT"`
+		Message  string    `properties:"message"`
+		Path     string    `properties:"path"`
+		Tags     []string  `properties:"tags" envSeparator:";"`
+		Database *Database `properties:"database"`
+	}
+
+	t.Run("load from file", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "8080")
+		data, err := os.ReadFile("testdata/info.properties")
+		if err != nil {
+			panic(err)
+		}
+		config := Config{}
+		if err := LoadPropertiesConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if config.Port != 8080 {
+			t.Errorf("Expected 8080 got config.Port=%d\n", config.Port)
+		}
+		if config.Host != "127.0.0.1" {
+			t.Errorf("Expected 127.0.0.1 got config.Host=%s\n", config.Host)
+		}
+	})
+	t.Run("syntax", func(t *testing.T) {
+		data := []byte(`# comment
+! another comment
+host example.com
+message = hello \
+          world\u0021
+path: C:\\temp
+tags=a;b
+database.host = db
+database.port : 5432
+`)
+		config := Config{}
+		if err := LoadPropertiesConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		expected := Config{
+			Host:     "example.com",
+			Message:  "hello world!",
+			Path:     `C:\temp`,
+			Tags:     []string{"a", "b"},
+			Database: &Database{Host: "db", Port: NewNullInt(5432)},
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("field errors", func(t *testing.T) {
+		err := LoadPropertiesConfiguration([]byte("host = a\ndatabase.port = x\n"), &Config{})
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) {
+			t.Fatalf("expected *FieldError, got %v", err)
+		}
+		expected := FieldError{Path: "Database.Port", Source: SourceProperties, Key: "database.port", Line: 2, Column: 17}
+		got := FieldError{Path: fieldErr.Path, Source: fieldErr.Source, Key: fieldErr.Key, Line: fieldErr.Line, Column: fieldErr.Column}
+		if got != expected {
+			t.Errorf("expected %+v, got %+v", expected, got)
+		}
+	})
+	t.Run("syntax errors", func(t *testing.T) {
+		err := LoadPropertiesConfiguration([]byte("host = a\nname = \\u00zz\n"), &Config{})
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != 2 {
+			t.Fatalf("expected *SyntaxError at line 2, got %v", err)
+		}
+	})
+}
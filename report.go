@@ -0,0 +1,107 @@
+package gottings
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Origin describes where the value of a field was set.
+type Origin struct {
+	// Path is the dotted path of the field, e.g. "Database.Port".
+	Path string
+	// Source is where the value came from.
+	Source Source
+	// Key is the name of the value in its source: the environment variable,
+	// the option or flag name or the document key path. It is empty for
+	// defaults.
+	Key string
+	// File is the configuration file the value was read from, when known.
+	File string
+	// Line and Column locate the value in File, when known.
+	Line   int
+	Column int
+	// Layer is the name of the Loader layer that set the value, if any.
+	Layer string
+	// Value is the value the field held once set, or "******" for the
+	// fields tagged as secret.
+	Value any
+	// Overridden lists the origins of the values this one replaced, oldest
+	// first.
+	Overridden []Origin
+}
+
+// Report records the origin of every field set by the calls it is passed
+// to with WithReport. The zero value is an empty report ready to use.
+type Report struct {
+	origins map[string]*Origin
+}
+
+// WithReport records in r the origin of every field the call sets. The
+// same report can be passed to several calls, e.g. LoadOptions followed by
+// LoadConfiguration, to track the values each call overrides:
+//
+//	var report gottings.Report
+//	err := gottings.LoadConfiguration(data, config, gottings.WithReport(&report))
+//	origin, _ := report.Lookup("Port")
+//	fmt.Println(origin.Source, origin.Key) // env APP_PORT
+func WithReport(r *Report) LoadOption {
+	return func(st *loadState) {
+		st.report = r
+	}
+}
+
+// Lookup returns the origin of the field at path.
+func (r *Report) Lookup(path string) (Origin, bool) {
+	origin, ok := r.origins[path]
+	if !ok {
+		return Origin{}, false
+	}
+	return *origin, true
+}
+
+// Origins returns the origin of every field that was set, sorted by path.
+func (r *Report) Origins() []Origin {
+	origins := make([]Origin, 0, len(r.origins))
+	for _, origin := range r.origins {
+		origins = append(origins, *origin)
+	}
+	sort.Slice(origins, func(i, j int) bool {
+		return origins[i].Path < origins[j].Path
+	})
+	return origins
+}
+
+func (r *Report) add(origin Origin) {
+	if r.origins == nil {
+		r.origins = map[string]*Origin{}
+	}
+	if previous, ok := r.origins[origin.Path]; ok {
+		overridden := *previous
+		overridden.Overridden = nil
+		origin.Overridden = append(previous.Overridden[:len(previous.Overridden):len(previous.Overridden)], overridden)
+	}
+	r.origins[origin.Path] = &origin
+}
+
+// record marks the field at origin.Path as set by the current call and,
+// when a report was requested, records its origin and value. The value of
+// a secret field is redacted.
+func (st *loadState) record(fieldValue reflect.Value, field reflect.StructField, origin Origin) {
+	st.set[origin.Path] = true
+	if st.report == nil {
+		return
+	}
+	origin.Layer = st.layer
+	if isSecretField(field) {
+		origin.Value = redacted
+	} else if fieldValue.Kind() == reflect.Pointer {
+		// Record what the pointer refers to, which a later source may
+		// overwrite in place.
+		if !fieldValue.IsNil() {
+			origin.Value = fieldValue.Elem().Interface()
+		}
+	} else {
+		origin.Value = fieldValue.Interface()
+	}
+	st.report.add(origin)
+}
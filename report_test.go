@@ -0,0 +1,135 @@
+package gottings
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReport(t *testing.T) {
+	type Database struct {
+		Host string `yaml:"host" env:"TEST_DB_HOST"`
+	}
+	type Config struct {
+		Host     string   `json:"host" env:"TEST_HOST" default:"127.0.0.1"`
+		Port     *int     `json:"port" env:"TEST_PORT"`
+		Debug    bool     `json:"debug"`
+		Name     string   `json:"name"`
+		Database Database `yaml:"database"`
+	}
+
+	t.Run("LoadConfiguration", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "9000")
+		var report Report
+		config := Config{}
+		err := LoadConfiguration([]byte(`{"host": "example.com", "port": 8080}`), &config, WithReport(&report))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		port, ok := report.Lookup("Port")
+		if !ok {
+			t.Fatalf("expected Port in report")
+		}
+		expected := Origin{
+			Path:       "Port",
+			Source:     SourceEnv,
+			Key:        "TEST_PORT",
+			Value:      9000,
+			Overridden: []Origin{{Path: "Port", Source: SourceJSON, Key: "port", Value: 8080}},
+		}
+		if !reflect.DeepEqual(port, expected) {
+			t.Errorf("expected %+v, got %+v", expected, port)
+		}
+
+		host, _ := report.Lookup("Host")
+		if host.Source != SourceJSON || len(host.Overridden) != 1 || host.Overridden[0].Source != SourceDefault {
+			t.Errorf("unexpected origin for Host: %+v", host)
+		}
+		if _, ok := report.Lookup("Debug"); ok {
+			t.Errorf("expected Debug to be absent from the report")
+		}
+	})
+	t.Run("secret fields are redacted", func(t *testing.T) {
+		type Config struct {
+			Password string `json:"password" env:"TEST_PASSWORD,secret"`
+			Token    string `json:"token" secret:"true"`
+		}
+		t.Setenv("TEST_PASSWORD", "from-env")
+		var report Report
+		data := []byte(`{"password": "from-json", "token": "abc"}`)
+		if err := LoadConfiguration(data, &Config{}, WithReport(&report)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		password, _ := report.Lookup("Password")
+		if password.Value != "******" || len(password.Overridden) != 1 || password.Overridden[0].Value != "******" {
+			t.Errorf("expected redacted values for Password, got %+v", password)
+		}
+		if token, _ := report.Lookup("Token"); token.Value != "******" {
+			t.Errorf("expected redacted value for Token, got %+v", token)
+		}
+	})
+	t.Run("several calls", func(t *testing.T) {
+		var report Report
+		config := Config{}
+		if err := LoadOptions(Options{"Name": "option"}, &config, WithReport(&report)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := LoadConfiguration([]byte(`{"name": "json"}`), &config, WithReport(&report)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		name, _ := report.Lookup("Name")
+		if name.Source != SourceJSON || name.Value != "json" {
+			t.Errorf("unexpected origin for Name: %+v", name)
+		}
+		if len(name.Overridden) != 1 || name.Overridden[0].Source != SourceOption || name.Overridden[0].Value != "option" {
+			t.Errorf("unexpected overridden values for Name: %+v", name.Overridden)
+		}
+		paths := []string{}
+		for _, origin := range report.Origins() {
+			paths = append(paths, origin.Path)
+		}
+		if !reflect.DeepEqual(paths, []string{"Host", "Name"}) {
+			t.Errorf("unexpected paths %v", paths)
+		}
+	})
+	t.Run("Loader", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"config.yaml": {Data: []byte("database:\n  host: db\n")},
+		}
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags.Bool("Debug", false, "")
+		if err := flags.Parse([]string{"-Debug"}); err != nil {
+			panic(err)
+		}
+		var report Report
+		err := NewLoader(
+			DefaultsLayer(),
+			FileLayerFS(fsys, "config.yaml"),
+			FlagLayer(flags),
+		).Load(&Config{}, WithReport(&report))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := []Origin{
+			{Path: "Database.Host", Source: SourceYAML, Key: "database.host", File: "config.yaml", Line: 2, Column: 9, Layer: "config.yaml", Value: "db"},
+			{Path: "Debug", Source: SourceFlag, Key: "Debug", Layer: "flags", Value: true},
+			{Path: "Host", Source: SourceDefault, Layer: "defaults", Value: "127.0.0.1"},
+		}
+		if origins := report.Origins(); !reflect.DeepEqual(origins, expected) {
+			t.Errorf("expected %+v, got %+v", expected, origins)
+		}
+	})
+	t.Run("line of string-valued formats", func(t *testing.T) {
+		var report Report
+		err := LoadINIConfiguration([]byte("\n[database]\nhost = db\n"), &Config{}, WithReport(&report))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		origin, _ := report.Lookup("Database.Host")
+		if origin.Line != 3 || origin.Column != 8 || origin.Key != "database.host" {
+			t.Errorf("unexpected origin %+v", origin)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package gottings
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// Secret holds a sensitive string, such as a password or an API key, that
+// is masked whenever it is printed or marshaled. The value can only be read
+// with Reveal:
+//
+//	type Config struct {
+//	     Password gottings.Secret `json:"password" env:"DB_PASSWORD"`
+//	}
+//
+//	fmt.Println(config.Password)       // ******
+//	db.Connect(config.Password.Reveal())
+//
+// Secret loads like a string from every source.
+type Secret struct {
+	// value is kept behind a pointer so that printing a struct holding a
+	// Secret in an unexported field shows an address, not the value.
+	value *string
+}
+
+// NewSecret returns a Secret holding s.
+func NewSecret(s string) Secret {
+	return Secret{value: &s}
+}
+
+// Reveal returns the value of the secret.
+func (s Secret) Reveal() string {
+	if s.value == nil {
+		return ""
+	}
+	return *s.value
+}
+
+func (s Secret) String() string {
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return "gottings.Secret(" + strconv.Quote(redacted) + ")"
+}
+
+// Format prints the mask for every verb.
+func (s Secret) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'q':
+		io.WriteString(f, strconv.Quote(redacted))
+	case verb == 'v' && f.Flag('#'):
+		io.WriteString(f, s.GoString())
+	default:
+		io.WriteString(f, redacted)
+	}
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	s.value = &value
+	return nil
+}
+
+func (s *Secret) UnmarshalEnvironmentValue(data []byte) error {
+	value := string(data)
+	s.value = &value
+	return nil
+}
+
+func (s *Secret) UnmarshalOption(v any) error {
+	var value string
+	if err := setOption(reflect.ValueOf(&value).Elem(), v); err != nil {
+		return err
+	}
+	s.value = &value
+	return nil
+}
+
+// NullSecret is a Secret that may be unset, as NullString is for strings.
+type NullSecret struct {
+	Secret Secret
+	Valid  bool
+}
+
+func (NullSecret) null() {}
+
+func NewNullSecret(s string) NullSecret {
+	return NullSecret{
+		Valid:  true,
+		Secret: NewSecret(s),
+	}
+}
+
+// Reveal returns the value of the secret, or "" when it is unset.
+func (s NullSecret) Reveal() string {
+	return s.Secret.Reveal()
+}
+
+func (s NullSecret) Value() Secret {
+	return s.Secret
+}
+
+func (s NullSecret) String() string {
+	return redacted
+}
+
+func (s NullSecret) GoString() string {
+	return "gottings.NullSecret(" + strconv.Quote(redacted) + ")"
+}
+
+// Format prints the mask for every verb.
+func (s NullSecret) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		io.WriteString(f, s.GoString())
+		return
+	}
+	s.Secret.Format(f, verb)
+}
+
+func (s NullSecret) MarshalJSON() ([]byte, error) {
+	return marshalNull(s.Secret, s.Valid)
+}
+
+func (s *NullSecret) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Secret, &s.Valid)
+}
+
+func (s *NullSecret) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Secret, &s.Valid)
+}
+
+func (s *NullSecret) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Secret, &s.Valid)
+}
@@ -0,0 +1,95 @@
+package gottings
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	type Config struct {
+		Password Secret     `json:"password" env:"TEST_PASSWORD"`
+		Token    NullSecret `json:"token" env:"TEST_TOKEN"`
+		APIKey   Secret     `json:"api_key"`
+		hidden   Secret
+	}
+
+	t.Run("load", func(t *testing.T) {
+		t.Setenv("TEST_PASSWORD", "hunter2")
+		config := Config{}
+		if err := LoadConfiguration([]byte(`{"token": "t0ken", "api_key": "k3y"}`), &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Password.Reveal() != "hunter2" {
+			t.Errorf("Expected hunter2 got %q", config.Password.Reveal())
+		}
+		if !config.Token.Valid || config.Token.Reveal() != "t0ken" {
+			t.Errorf("Expected valid t0ken got %v %q", config.Token.Valid, config.Token.Reveal())
+		}
+		if config.APIKey.Reveal() != "k3y" {
+			t.Errorf("Expected k3y got %q", config.APIKey.Reveal())
+		}
+
+		options := Config{}
+		if err := LoadOptions(Options{"Password": "hunter2", "Token": nil, "APIKey": NewSecret("k3y")}, &options); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if options.Password.Reveal() != "hunter2" || options.Token.Valid || options.APIKey.Reveal() != "k3y" {
+			t.Errorf("unexpected options %#v", options)
+		}
+
+		null := Config{}
+		if err := LoadConfiguration([]byte(`{"token": null}`), &null); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if null.Token.Valid || null.Token.Reveal() != "" {
+			t.Errorf("expected unset token, got %v", null.Token.Valid)
+		}
+	})
+	t.Run("never printed", func(t *testing.T) {
+		config := Config{
+			Password: NewSecret("hunter2"),
+			Token:    NewNullSecret("t0ken"),
+			APIKey:   NewSecret("k3y"),
+			hidden:   NewSecret("h1dden"),
+		}
+		data, err := json.Marshal(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		outputs := []string{
+			string(data),
+			config.Password.String(),
+			config.Password.GoString(),
+			config.Token.String(),
+			config.Token.GoString(),
+		}
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+			outputs = append(outputs, fmt.Sprintf(format, config), fmt.Sprintf(format, &config))
+		}
+		for _, output := range outputs {
+			for _, value := range []string{"hunter2", "t0ken", "k3y", "h1dden"} {
+				if strings.Contains(output, value) {
+					t.Errorf("%s leaked in %s", value, output)
+				}
+			}
+		}
+		if string(data) != `{"password":"******","token":"******","api_key":"******"}` {
+			t.Errorf("unexpected JSON %s", data)
+		}
+		if s := fmt.Sprint(config.Password); s != "******" {
+			t.Errorf("expected ******, got %s", s)
+		}
+		if s := fmt.Sprintf("%#v", config.Password); s != `gottings.Secret("******")` {
+			t.Errorf(`expected gottings.Secret("******"), got %s`, s)
+		}
+		if s := fmt.Sprintf("%#v", NewNullSecret("hunter2")); s != `gottings.NullSecret("******")` {
+			t.Errorf(`expected gottings.NullSecret("******"), got %s`, s)
+		}
+		unset, _ := json.Marshal(NullSecret{})
+		if string(unset) != "null" {
+			t.Errorf("expected null, got %s", unset)
+		}
+	})
+}
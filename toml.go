@@ -0,0 +1,40 @@
+package gottings
+
+import (
+	"reflect"
+
+	"github.com/BurntSushi/toml"
+)
+
+var tomlDecoder = &treeDecoder{
+	tag:    "toml",
+	source: SourceTOML,
+	scalar: func(target reflect.Value, value any, _ reflect.StructTag) error {
+		return setOption(target, value)
+	},
+}
+
+// LoadTOMLConfiguration is like LoadConfiguration but reads a TOML
+// document. Keys are matched against the toml tag of each field, then its
+// json tag, then its name, case-insensitively. Tables fill nested structs,
+// arrays of tables fill slices of structs and datetimes fill time.Time
+// fields. Values are converted as LoadOptions converts them.
+//
+//	type Config struct {
+//	     Host     string           `toml:"host" env:"APP_HOST"`
+//	     Port     gottings.NullInt `toml:"port" env:"APP_PORT"`
+//	     Replicas []Replica        `toml:"replica"`
+//	}
+func LoadTOMLConfiguration(data []byte, v any, opts ...LoadOption) error {
+	return loadConfiguration(data, v, opts, (*loadState).loadTOMLDocument)
+}
+
+// loadTOMLDocument decodes the TOML document data into elem.
+func (st *loadState) loadTOMLDocument(elem reflect.Value, data []byte) error {
+	var table map[string]any
+	if _, err := toml.Decode(string(data), &table); err != nil {
+		return err
+	}
+	tomlDecoder.decode(st, elem, table, "", "")
+	return nil
+}
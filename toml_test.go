@@ -0,0 +1,188 @@
+package gottings
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadTOMLConfiguration(t *testing.T) {
+	type Database struct {
+		Host string  `toml:"host"`
+		Port NullInt `toml:"port"`
+	}
+	type Replica struct {
+		Name   string `toml:"name"`
+		Weight int8   `toml:"weight"`
+	}
+	type Config struct {
+		Host     string              `toml:"host" env:"TEST_HOST"`
+		Port     int                 `json:"port" env:"TEST_PORT"`
+		Ratio    float32             `toml:"ratio"`
+		Debug    NullBool            `toml:"debug"`
+		Timeout  Null[time.Duration] `toml:"timeout"`
+		Started  time.Time           `toml:"started"`
+		Tags     []string            `toml:"tags"`
+		Labels   map[string]string   `toml:"labels"`
+		Database *Database           `toml:"database"`
+		Replicas []Replica           `toml:"replica"`
+	}
+
+	t.Run("load from file", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "8080")
+		data, err := os.ReadFile("testdata/info.toml")
+		if err != nil {
+			panic(err)
+		}
+		config := Config{}
+		if err := LoadTOMLConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if config.Port != 8080 {
+			t.Errorf("Expected 8080 got config.Port=%d\n", config.Port)
+		}
+		if config.Host != "127.0.0.1" {
+			t.Errorf("Expected 127.0.0.1 got config.Host=%s\n", config.Host)
+		}
+	})
+	t.Run("all supported values", func(t *testing.T) {
+		data := []byte(`
+host = "example.com"
+port = 8080
+ratio = 0.5
+debug = true
+timeout = "1m30s"
+started = 2024-01-02T03:04:05Z
+tags = ["a", "b"]
+labels = { team = "core" }
+
+[database]
+host = "db"
+port = 5432
+
+[[replica]]
+name = "r1"
+weight = 1
+
+[[replica]]
+name = "r2"
+`)
+		config := Config{}
+		if err := LoadTOMLConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		expected := Config{
+			Host:     "example.com",
+			Port:     8080,
+			Ratio:    0.5,
+			Debug:    NewNullBool(true),
+			Timeout:  NewNull(90 * time.Second),
+			Started:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Tags:     []string{"a", "b"},
+			Labels:   map[string]string{"team": "core"},
+			Database: &Database{Host: "db", Port: NewNullInt(5432)},
+			Replicas: []Replica{{Name: "r1", Weight: 1}, {Name: "r2"}},
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("environment overrides the file", func(t *testing.T) {
+		t.Setenv("TEST_HOST", "env.example.com")
+		config := Config{}
+		if err := LoadTOMLConfiguration([]byte(`host = "example.com"`), &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if config.Host != "env.example.com" {
+			t.Errorf("Expected env.example.com got config.Host=%s\n", config.Host)
+		}
+	})
+	t.Run("field errors", func(t *testing.T) {
+		data := []byte("port = \"http\"\n[database]\nport = \"x\"\n[[replica]]\nname = \"r1\"\n[[replica]]\nweight = \"heavy\"\n")
+		err := LoadTOMLConfiguration(data, &Config{})
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Fatalf("expected 3 errors, got %v", err)
+		}
+		expected := []FieldError{
+			{Path: "Port", Source: SourceTOML, Key: "port"},
+			{Path: "Database.Port", Source: SourceTOML, Key: "database.port"},
+			{Path: "Replicas[1].Weight", Source: SourceTOML, Key: "replica[1].weight"},
+		}
+		for i, err := range errs {
+			fieldErr := err.(*FieldError)
+			got := FieldError{Path: fieldErr.Path, Source: fieldErr.Source, Key: fieldErr.Key}
+			if got != expected[i] {
+				t.Errorf("expected %+v, got %+v", expected[i], got)
+			}
+		}
+	})
+	t.Run("unsigned and complex values", func(t *testing.T) {
+		type Config struct {
+			Port  uint16     `toml:"port"`
+			Mask  NullUint8  `toml:"mask"`
+			Sizes []uint64   `toml:"sizes"`
+			Phase complex128 `toml:"phase"`
+			Gain  complex64  `toml:"gain"`
+		}
+		config := Config{}
+		if err := LoadTOMLConfiguration([]byte("port = 8080\nmask = 255\nsizes = [1, 2]\nphase = \"1+2i\"\ngain = 0.5\n"), &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Config{
+			Port:  8080,
+			Mask:  NewNullUint8(255),
+			Sizes: []uint64{1, 2},
+			Phase: complex(1, 2),
+			Gain:  0.5,
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v", config, expected)
+		}
+		if err := LoadTOMLConfiguration([]byte("port = -1\n"), &Config{}); err == nil {
+			t.Fatalf("expected error, got %v", err)
+		}
+	})
+	t.Run("keys differing in case", func(t *testing.T) {
+		type Config struct {
+			Port int `toml:"port"`
+		}
+		for i := 0; i < 20; i++ {
+			config := Config{}
+			if err := LoadTOMLConfiguration([]byte("Port = 1\nPORT = 2\n"), &config); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if config.Port != 2 {
+				t.Fatalf("expected the value of PORT, got %d", config.Port)
+			}
+		}
+	})
+	t.Run("embedded pointer to unexported struct", func(t *testing.T) {
+		type inner struct {
+			Host string `toml:"host"`
+		}
+		type Config struct {
+			*inner
+			Port int `toml:"port"`
+		}
+		config := Config{}
+		err := LoadTOMLConfiguration([]byte("host = \"x\"\nport = 1\n"), &config)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Path != "inner" || fieldErr.Source != SourceTOML {
+			t.Fatalf("expected *FieldError for inner, got %v", err)
+		}
+		if config.Port != 1 || config.inner != nil {
+			t.Errorf("unexpected result %+v", config)
+		}
+		if err := LoadTOMLConfiguration([]byte("port = 1\n"), &Config{}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		if err := LoadTOMLConfiguration([]byte("host = "), &Config{}); err == nil {
+			t.Fatalf("expected error, got %v", err)
+		}
+	})
+}
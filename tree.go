@@ -0,0 +1,233 @@
+package gottings
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// treeDecoder stores a decoded document made of map[string]any, slices and
+// scalar values into a struct. It backs the formats whose parser produces
+// such a tree rather than decoding into Go values itself.
+type treeDecoder struct {
+	// tag names the struct tag holding the key of each field. The json tag
+	// and then the field name are used when it is absent.
+	tag    string
+	source Source
+	// scalar stores a value that is neither a table nor a list.
+	scalar func(target reflect.Value, value any, tag reflect.StructTag) error
+}
+
+// decode stores the table into the struct elem. path is the field path of
+// elem and keyPath its key path in the document. It reports whether any
+// field was set.
+func (d *treeDecoder) decode(st *loadState, elem reflect.Value, table map[string]any, path, keyPath string) bool {
+	set := false
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		fieldValue := elem.Field(i)
+		name := d.fieldKey(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && isNestedStruct(field.Type) {
+			set = st.descendEmbedded(fieldValue, joinPath(path, field.Name), d.source, keyPath, func(st *loadState, nested reflect.Value) bool {
+				return d.decode(st, nested, table, path, keyPath)
+			}) || set
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		key, value, ok := lookupTreeKey(table, name)
+		if !ok {
+			continue
+		}
+		fieldPath := joinPath(path, field.Name)
+		fieldKeyPath := joinPath(keyPath, key)
+		if nested, ok := value.(map[string]any); ok && isNestedStruct(field.Type) {
+			set = descend(fieldValue, func(elem reflect.Value) bool {
+				return d.decode(st, elem, nested, fieldPath, fieldKeyPath)
+			}) || set
+			continue
+		}
+		var line, column int
+		if str, ok := value.(*stringValue); ok {
+			line, column = str.line, str.column
+		}
+		if err := d.decodeValue(fieldValue, value, field.Tag, fieldPath, fieldKeyPath); err != nil {
+			st.addFieldError(&FieldError{Path: fieldPath, Source: d.source, Key: fieldKeyPath, Line: line, Column: column, Err: err})
+			continue
+		}
+		st.record(fieldValue, field, Origin{Path: fieldPath, Source: d.source, Key: fieldKeyPath, File: st.file, Line: line, Column: column})
+		set = true
+	}
+	return set
+}
+
+// decodeValue stores value into target, which is not walked field by field.
+// path is the field path of target and keyPath its key path, which the
+// errors of the structs found in lists and maps carry.
+func (d *treeDecoder) decodeValue(target reflect.Value, value any, tag reflect.StructTag, path, keyPath string) error {
+	if target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return d.decodeValue(target.Elem(), value, tag, path, keyPath)
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case target.Kind() == reflect.Struct && rv.Kind() == reflect.Map && isNestedStruct(target.Type()):
+		// Structs reached through lists and maps are decoded on their own.
+		st := newLoadState(nil)
+		d.decode(st, target, value.(map[string]any), path, keyPath)
+		return st.err()
+	case target.Kind() == reflect.Slice && rv.Kind() == reflect.Slice && !hasUnmarshaler(target.Type()):
+		slice := reflect.MakeSlice(target.Type(), rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			if err := d.decodeValue(slice.Index(i), rv.Index(i).Interface(), tag, indexPath(path, i), indexPath(keyPath, i)); err != nil {
+				return elementError(i, err)
+			}
+		}
+		target.Set(slice)
+	case target.Kind() == reflect.Array && rv.Kind() == reflect.Slice:
+		if rv.Len() > target.Len() {
+			return fmt.Errorf("got %d elements for array of length %d", rv.Len(), target.Len())
+		}
+		array := reflect.New(target.Type()).Elem()
+		for i := 0; i < rv.Len(); i++ {
+			if err := d.decodeValue(array.Index(i), rv.Index(i).Interface(), tag, indexPath(path, i), indexPath(keyPath, i)); err != nil {
+				return elementError(i, err)
+			}
+		}
+		target.Set(array)
+	case target.Kind() == reflect.Map && rv.Kind() == reflect.Map:
+		m := reflect.MakeMap(target.Type())
+		for _, key := range rv.MapKeys() {
+			keyValue := reflect.New(target.Type().Key()).Elem()
+			if err := setScalar(keyValue, key.String()); err != nil {
+				return fmt.Errorf("key %q: %w", key.String(), err)
+			}
+			elemValue := reflect.New(target.Type().Elem()).Elem()
+			if err := d.decodeValue(elemValue, rv.MapIndex(key).Interface(), tag, keyedPath(path, key.String()), joinPath(keyPath, key.String())); err != nil {
+				return valueError(key.String(), err)
+			}
+			m.SetMapIndex(keyValue, elemValue)
+		}
+		target.Set(m)
+	default:
+		return d.scalar(target, value, tag)
+	}
+	return nil
+}
+
+func (d *treeDecoder) fieldKey(field reflect.StructField) string {
+	if name, _ := parseTag(field.Tag.Get(d.tag)); name != "" {
+		return name
+	}
+	name, _ := parseTag(field.Tag.Get("json"))
+	return name
+}
+
+// lookupTreeKey finds name in table, preferring an exact match over a
+// case-insensitive one. Among several case-insensitive matches, the first
+// key in sorted order is taken, so that the same key wins on every run.
+func lookupTreeKey(table map[string]any, name string) (string, any, bool) {
+	if value, ok := table[name]; ok {
+		return name, value, true
+	}
+	found := false
+	var match string
+	for key := range table {
+		if strings.EqualFold(key, name) && (!found || key < match) {
+			match, found = key, true
+		}
+	}
+	if !found {
+		return "", nil, false
+	}
+	return match, table[match], true
+}
+
+// indexPath returns the path of element i of the list at path, e.g.
+// "Replicas[1]".
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// keyedPath returns the path of the value under key in the map at path,
+// e.g. "Servers[web]".
+func keyedPath(path, key string) string {
+	return path + "[" + key + "]"
+}
+
+// elementError attributes err to element i of a list. The field errors of
+// a struct element carry its path already and are returned as they are.
+func elementError(i int, err error) error {
+	if _, ok := err.(Errors); ok {
+		return err
+	}
+	return fmt.Errorf("element %d: %w", i, err)
+}
+
+// valueError is elementError for the value under key in a map.
+func valueError(key string, err error) error {
+	if _, ok := err.(Errors); ok {
+		return err
+	}
+	return fmt.Errorf("value for key %q: %w", key, err)
+}
+
+// stringValue is a leaf of the trees built by the string-valued formats. It
+// keeps the position of the value for error reporting.
+type stringValue struct {
+	raw    string
+	line   int
+	column int
+}
+
+// stringTreeDecoder returns a treeDecoder for a string-valued format. Leaves
+// are converted as LoadEnv converts environment values, so lists and maps
+// honour the envSeparator and envKeyValSeparator tags.
+func stringTreeDecoder(tag string, source Source) *treeDecoder {
+	return &treeDecoder{
+		tag:    tag,
+		source: source,
+		scalar: func(target reflect.Value, value any, tag reflect.StructTag) error {
+			str, ok := value.(*stringValue)
+			if !ok {
+				return fmt.Errorf("cannot use a table as %s", target.Type())
+			}
+			return setEnvValue(target, str.raw, tag)
+		},
+	}
+}
+
+// insertTreeValue stores value in root under the dotted key, creating the
+// intermediate tables. It fails when a key is used both as a value and as
+// a table.
+func insertTreeValue(root map[string]any, key string, value *stringValue) error {
+	names := strings.Split(key, ".")
+	table := root
+	for i, name := range names[:len(names)-1] {
+		switch next := table[name].(type) {
+		case nil:
+			nested := map[string]any{}
+			table[name] = nested
+			table = nested
+		case map[string]any:
+			table = next
+		default:
+			return fmt.Errorf("key %s is both a value and a section", strings.Join(names[:i+1], "."))
+		}
+	}
+	last := names[len(names)-1]
+	if _, ok := table[last].(map[string]any); ok {
+		return fmt.Errorf("key %s is both a value and a section", key)
+	}
+	table[last] = value
+	return nil
+}
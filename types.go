@@ -3,9 +3,130 @@ package gottings
 import (
 	"bytes"
 	"encoding/json"
-	"strconv"
+	"reflect"
+	"time"
 )
 
+// Null is a value of type T that may be unset. It supports every scalar kind
+// as well as time.Duration, time.Time and any type implementing
+// encoding.TextUnmarshaler or UnmarshalableField.
+//
+//	type Config struct {
+//	     Timeout gottings.Null[time.Duration] `json:"timeout" env:"APP_TIMEOUT"`
+//	}
+//
+// The NullString, NullInt, ... types predate Null and are kept for
+// compatibility; they behave exactly like their Null[T] counterpart.
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+func NewNull[T any](v T) Null[T] {
+	return Null[T]{
+		Valid: true,
+		V:     v,
+	}
+}
+
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	return marshalNull(n.V, n.Valid)
+}
+
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &n.V, &n.Valid)
+}
+
+func (n *Null[T]) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &n.V, &n.Valid)
+}
+
+func (n *Null[T]) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &n.V, &n.Valid)
+}
+
+func (n Null[T]) Value() T {
+	return n.V
+}
+
+func marshalNull[T any](v T, valid bool) ([]byte, error) {
+	if !valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v)
+}
+
+func unmarshalNullJSON[T any](data []byte, v *T, valid *bool) error {
+	if bytes.Equal(data, []byte("null")) {
+		*valid = false
+		return nil
+	}
+
+	// Durations are accepted both as nanoseconds and as "1m30s" strings.
+	if d, ok := any(v).(*time.Duration); ok && len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		value, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = value
+	} else if err := unmarshalJSONValue(reflect.ValueOf(v).Elem(), data); err != nil {
+		return err
+	}
+
+	*valid = true
+	return nil
+}
+
+func unmarshalNullEnvironmentValue[T any](data []byte, v *T, valid *bool) error {
+	if err := setScalar(reflect.ValueOf(v).Elem(), string(data)); err != nil {
+		return err
+	}
+	*valid = true
+	return nil
+}
+
+// unmarshalNullOption converts data the same way LoadOptions converts plain
+// fields, so that an int option fills a NullInt8 and a *string taken from a
+// flag fills a NullString. A nil data marks the value as unset.
+func unmarshalNullOption[T any](data any, v *T, valid *bool) error {
+	if data == nil {
+		*valid = false
+		return nil
+	}
+	if err := setOption(reflect.ValueOf(v).Elem(), data); err != nil {
+		return err
+	}
+	*valid = true
+	return nil
+}
+
+// nullable is implemented by the Null types, which dumps and flags treat as
+// a single value that is unset unless Valid is true.
+type nullable interface {
+	null()
+}
+
+func (Null[T]) null() {}
+
+func (NullString) null()  {}
+func (NullBool) null()    {}
+func (NullFloat32) null() {}
+func (NullFloat64) null() {}
+func (NullInt) null()     {}
+func (NullInt8) null()    {}
+func (NullInt16) null()   {}
+func (NullInt32) null()   {}
+func (NullInt64) null()   {}
+func (NullUint) null()    {}
+func (NullUint8) null()   {}
+func (NullUint16) null()  {}
+func (NullUint32) null()  {}
+func (NullUint64) null()  {}
+
 type NullString struct {
 	String string
 	Valid  bool
@@ -51,6 +172,31 @@ type NullInt64 struct {
 	Valid bool
 }
 
+type NullUint struct {
+	Uint  uint
+	Valid bool
+}
+
+type NullUint8 struct {
+	Uint8 uint8
+	Valid bool
+}
+
+type NullUint16 struct {
+	Uint16 uint16
+	Valid  bool
+}
+
+type NullUint32 struct {
+	Uint32 uint32
+	Valid  bool
+}
+
+type NullUint64 struct {
+	Uint64 uint64
+	Valid  bool
+}
+
 func NewNullString(s string) NullString {
 	return NullString{
 		Valid:  true,
@@ -93,6 +239,41 @@ func NewNullInt64(i int64) NullInt64 {
 	}
 }
 
+func NewNullUint(u uint) NullUint {
+	return NullUint{
+		Valid: true,
+		Uint:  u,
+	}
+}
+
+func NewNullUint8(u uint8) NullUint8 {
+	return NullUint8{
+		Valid: true,
+		Uint8: u,
+	}
+}
+
+func NewNullUint16(u uint16) NullUint16 {
+	return NullUint16{
+		Valid:  true,
+		Uint16: u,
+	}
+}
+
+func NewNullUint32(u uint32) NullUint32 {
+	return NullUint32{
+		Valid:  true,
+		Uint32: u,
+	}
+}
+
+func NewNullUint64(u uint64) NullUint64 {
+	return NullUint64{
+		Valid:  true,
+		Uint64: u,
+	}
+}
+
 func NewNullFloat32(d float32) NullFloat32 {
 	return NullFloat32{
 		Valid:   true,
@@ -115,330 +296,225 @@ func NewNullBool(b bool) NullBool {
 }
 
 func (s NullString) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.String)
+	return marshalNull(s.String, s.Valid)
 }
 
 func (s NullInt) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.Int)
+	return marshalNull(s.Int, s.Valid)
 }
 
 func (s NullInt8) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.Int8)
+	return marshalNull(s.Int8, s.Valid)
 }
 
 func (s NullInt16) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.Int16)
+	return marshalNull(s.Int16, s.Valid)
 }
 
 func (s NullInt32) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.Int32)
+	return marshalNull(s.Int32, s.Valid)
 }
 
 func (s NullInt64) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.Int64)
+	return marshalNull(s.Int64, s.Valid)
+}
+
+func (s NullUint) MarshalJSON() ([]byte, error) {
+	return marshalNull(s.Uint, s.Valid)
+}
+
+func (s NullUint8) MarshalJSON() ([]byte, error) {
+	return marshalNull(s.Uint8, s.Valid)
+}
+
+func (s NullUint16) MarshalJSON() ([]byte, error) {
+	return marshalNull(s.Uint16, s.Valid)
+}
+
+func (s NullUint32) MarshalJSON() ([]byte, error) {
+	return marshalNull(s.Uint32, s.Valid)
+}
+
+func (s NullUint64) MarshalJSON() ([]byte, error) {
+	return marshalNull(s.Uint64, s.Valid)
 }
 
 func (s NullFloat32) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.Float32)
+	return marshalNull(s.Float32, s.Valid)
 }
 
 func (s NullFloat64) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.Float64)
+	return marshalNull(s.Float64, s.Valid)
 }
 
 func (s NullBool) MarshalJSON() ([]byte, error) {
-	if !s.Valid {
-		return []byte("null"), nil
-	}
-	return json.Marshal(s.Bool)
+	return marshalNull(s.Bool, s.Valid)
 }
 
 func (s *NullString) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
-
-	if err := json.Unmarshal(data, &s.String); err != nil {
-		return err
-	}
-
-	s.Valid = true
-	return nil
+	return unmarshalNullJSON(data, &s.String, &s.Valid)
 }
 
 func (s *NullString) UnmarshalEnvironmentValue(data []byte) error {
-	s.Valid = true
-	s.String = string(data)
-	return nil
+	return unmarshalNullEnvironmentValue(data, &s.String, &s.Valid)
 }
 
-func (s *NullString) UnmarshalOption(data string) error {
-	s.Valid = true
-	s.String = data
-	return nil
+func (s *NullString) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.String, &s.Valid)
 }
 
 func (s *NullInt) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
-
-	if err := json.Unmarshal(data, &s.Int); err != nil {
-		return err
-	}
-
-	s.Valid = true
-	return nil
+	return unmarshalNullJSON(data, &s.Int, &s.Valid)
 }
 
 func (s *NullInt) UnmarshalEnvironmentValue(data []byte) error {
-	value, err := strconv.ParseInt(string(data), 10, 0)
-	if err != nil {
-		return err
-	}
-	s.Valid = true
-	s.Int = int(value)
-	return nil
+	return unmarshalNullEnvironmentValue(data, &s.Int, &s.Valid)
 }
 
-func (s *NullInt) UnmarshalOption(data int) error {
-	s.Valid = true
-	s.Int = data
-	return nil
+func (s *NullInt) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Int, &s.Valid)
 }
 
 func (s *NullInt8) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
-
-	if err := json.Unmarshal(data, &s.Int8); err != nil {
-		return err
-	}
-
-	s.Valid = true
-	return nil
+	return unmarshalNullJSON(data, &s.Int8, &s.Valid)
 }
 
 func (s *NullInt8) UnmarshalEnvironmentValue(data []byte) error {
-	value, err := strconv.ParseInt(string(data), 10, 8)
-	if err != nil {
-		return err
-	}
-	s.Valid = true
-	s.Int8 = int8(value)
-	return nil
+	return unmarshalNullEnvironmentValue(data, &s.Int8, &s.Valid)
 }
 
-func (s *NullInt8) UnmarshalOption(data int8) error {
-	s.Valid = true
-	s.Int8 = data
-	return nil
+func (s *NullInt8) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Int8, &s.Valid)
 }
 
 func (s *NullInt16) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
-
-	if err := json.Unmarshal(data, &s.Int16); err != nil {
-		return err
-	}
-
-	s.Valid = true
-	return nil
+	return unmarshalNullJSON(data, &s.Int16, &s.Valid)
 }
 
 func (s *NullInt16) UnmarshalEnvironmentValue(data []byte) error {
-	value, err := strconv.ParseInt(string(data), 10, 16)
-	if err != nil {
-		return err
-	}
-	s.Valid = true
-	s.Int16 = int16(value)
-	return nil
+	return unmarshalNullEnvironmentValue(data, &s.Int16, &s.Valid)
 }
-func (s *NullInt16) UnmarshalOption(data int16) error {
-	s.Valid = true
-	s.Int16 = data
-	return nil
+func (s *NullInt16) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Int16, &s.Valid)
 }
 
 func (s *NullInt32) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
-
-	if err := json.Unmarshal(data, &s.Int32); err != nil {
-		return err
-	}
-
-	s.Valid = true
-	return nil
+	return unmarshalNullJSON(data, &s.Int32, &s.Valid)
 }
 
 func (s *NullInt32) UnmarshalEnvironmentValue(data []byte) error {
-	value, err := strconv.ParseInt(string(data), 10, 32)
-	if err != nil {
-		return err
-	}
-	s.Valid = true
-	s.Int32 = int32(value)
-	return nil
+	return unmarshalNullEnvironmentValue(data, &s.Int32, &s.Valid)
 }
-func (s *NullInt32) UnmarshalOption(data int32) error {
-	s.Valid = true
-	s.Int32 = data
-	return nil
+func (s *NullInt32) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Int32, &s.Valid)
 }
 
 func (s *NullInt64) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
+	return unmarshalNullJSON(data, &s.Int64, &s.Valid)
+}
 
-	if err := json.Unmarshal(data, &s.Int64); err != nil {
-		return err
-	}
+func (s *NullInt64) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Int64, &s.Valid)
+}
 
-	s.Valid = true
-	return nil
+func (s *NullInt64) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Int64, &s.Valid)
 }
 
-func (s *NullInt64) UnmarshalEnvironmentValue(data []byte) error {
-	value, err := strconv.ParseInt(string(data), 10, 64)
-	if err != nil {
-		return err
-	}
-	s.Valid = true
-	s.Int64 = value
-	return nil
+func (s *NullUint) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Uint, &s.Valid)
 }
 
-func (s *NullInt64) UnmarshalOption(data int64) error {
-	s.Valid = true
-	s.Int64 = data
-	return nil
+func (s *NullUint) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Uint, &s.Valid)
 }
 
-func (s *NullFloat32) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
+func (s *NullUint) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Uint, &s.Valid)
+}
 
-	if err := json.Unmarshal(data, &s.Float32); err != nil {
-		return err
-	}
+func (s *NullUint8) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Uint8, &s.Valid)
+}
 
-	s.Valid = true
-	return nil
+func (s *NullUint8) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Uint8, &s.Valid)
 }
 
-func (s *NullFloat32) UnmarshalEnvironmentValue(data []byte) error {
-	value, err := strconv.ParseFloat(string(data), 32)
-	if err != nil {
-		return err
-	}
-	s.Valid = true
-	s.Float32 = float32(value)
-	return nil
+func (s *NullUint8) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Uint8, &s.Valid)
 }
 
-func (s *NullFloat32) UnmarshalOption(data float32) error {
-	s.Valid = true
-	s.Float32 = data
-	return nil
+func (s *NullUint16) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Uint16, &s.Valid)
 }
 
-func (s *NullFloat64) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
+func (s *NullUint16) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Uint16, &s.Valid)
+}
 
-	if err := json.Unmarshal(data, &s.Float64); err != nil {
-		return err
-	}
+func (s *NullUint16) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Uint16, &s.Valid)
+}
 
-	s.Valid = true
-	return nil
+func (s *NullUint32) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Uint32, &s.Valid)
 }
 
-func (s *NullFloat64) UnmarshalEnvironmentValue(data []byte) error {
-	value, err := strconv.ParseFloat(string(data), 64)
-	if err != nil {
-		return err
-	}
-	s.Valid = true
-	s.Float64 = value
-	return nil
+func (s *NullUint32) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Uint32, &s.Valid)
 }
 
-func (s *NullFloat64) UnmarshalOption(data float64) error {
-	s.Valid = true
-	s.Float64 = data
-	return nil
+func (s *NullUint32) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Uint32, &s.Valid)
 }
 
-func (s *NullBool) UnmarshalJSON(data []byte) error {
-	if bytes.Equal(data, []byte("null")) {
-		s.Valid = false
-		return nil
-	}
+func (s *NullUint64) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Uint64, &s.Valid)
+}
 
-	if err := json.Unmarshal(data, &s.Bool); err != nil {
-		return err
-	}
+func (s *NullUint64) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Uint64, &s.Valid)
+}
 
-	s.Valid = true
-	return nil
+func (s *NullUint64) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Uint64, &s.Valid)
+}
+
+func (s *NullFloat32) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Float32, &s.Valid)
+}
+
+func (s *NullFloat32) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Float32, &s.Valid)
+}
+
+func (s *NullFloat32) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Float32, &s.Valid)
+}
+
+func (s *NullFloat64) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Float64, &s.Valid)
+}
+
+func (s *NullFloat64) UnmarshalEnvironmentValue(data []byte) error {
+	return unmarshalNullEnvironmentValue(data, &s.Float64, &s.Valid)
+}
+
+func (s *NullFloat64) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Float64, &s.Valid)
+}
+
+func (s *NullBool) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &s.Bool, &s.Valid)
 }
 
 func (s *NullBool) UnmarshalEnvironmentValue(data []byte) error {
-	value, err := strconv.ParseBool(string(data))
-	if err != nil {
-		return err
-	}
-	s.Valid = true
-	s.Bool = value
-	return nil
+	return unmarshalNullEnvironmentValue(data, &s.Bool, &s.Valid)
 }
 
-func (s *NullBool) UnmarshalOption(data bool) error {
-	s.Valid = true
-	s.Bool = data
-	return nil
+func (s *NullBool) UnmarshalOption(v any) error {
+	return unmarshalNullOption(v, &s.Bool, &s.Valid)
 }
 
 func (b NullBool) Value() bool {
@@ -469,6 +545,26 @@ func (i NullInt64) Value() int64 {
 	return i.Int64
 }
 
+func (u NullUint) Value() uint {
+	return u.Uint
+}
+
+func (u NullUint8) Value() uint8 {
+	return u.Uint8
+}
+
+func (u NullUint16) Value() uint16 {
+	return u.Uint16
+}
+
+func (u NullUint32) Value() uint32 {
+	return u.Uint32
+}
+
+func (u NullUint64) Value() uint64 {
+	return u.Uint64
+}
+
 func (f NullFloat32) Value() float32 {
 	return f.Float32
 }
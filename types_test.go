@@ -2,7 +2,10 @@ package gottings
 
 import (
 	"encoding/json"
+	"net/netip"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNullString(t *testing.T) {
@@ -602,3 +605,192 @@ func TestNullFloat64Value(t *testing.T) {
 		})
 	}
 }
+
+func TestNullUint(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		type Config struct {
+			Uint   NullUint   `json:"uint"`
+			Uint8  NullUint8  `json:"uint8"`
+			Uint16 NullUint16 `json:"uint16"`
+			Uint32 NullUint32 `json:"uint32"`
+			Uint64 NullUint64 `json:"uint64"`
+		}
+		config := &Config{}
+		data := []byte(`{"uint": 1, "uint8": 255, "uint16": 8080, "uint32": 4294967295, "uint64": null}`)
+		if err := json.Unmarshal(data, config); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := Config{
+			Uint:   NewNullUint(1),
+			Uint8:  NewNullUint8(255),
+			Uint16: NewNullUint16(8080),
+			Uint32: NewNullUint32(4294967295),
+		}
+		if *config != expected {
+			t.Fatalf("Expected %+v, got %+v", expected, *config)
+		}
+
+		if err := json.Unmarshal([]byte(`{"uint8": -1}`), config); err == nil {
+			t.Fatalf("Expected error for negative uint8, got %+v", config.Uint8)
+		}
+
+		raw, err := json.Marshal(Config{Uint64: NewNullUint64(18446744073709551615)})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expectedRaw := `{"uint":null,"uint8":null,"uint16":null,"uint32":null,"uint64":18446744073709551615}`
+		if string(raw) != expectedRaw {
+			t.Fatalf("Expected data %v got %v", expectedRaw, string(raw))
+		}
+	})
+	t.Run("environment", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			value    string
+			target   interface{ UnmarshalEnvironmentValue([]byte) error }
+			expected any
+			hasError bool
+		}{
+			{name: "uint", value: "42", target: &NullUint{}, expected: &NullUint{Uint: 42, Valid: true}},
+			{name: "uint8", value: "255", target: &NullUint8{}, expected: &NullUint8{Uint8: 255, Valid: true}},
+			{name: "uint8 overflow", value: "256", target: &NullUint8{}, hasError: true},
+			{name: "uint16", value: "8080", target: &NullUint16{}, expected: &NullUint16{Uint16: 8080, Valid: true}},
+			{name: "uint32 negative", value: "-1", target: &NullUint32{}, hasError: true},
+			{name: "uint64", value: "18446744073709551615", target: &NullUint64{}, expected: &NullUint64{Uint64: 18446744073709551615, Valid: true}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := tc.target.UnmarshalEnvironmentValue([]byte(tc.value))
+				if tc.hasError {
+					if err == nil {
+						t.Fatalf("Expected error, got %+v", tc.target)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if !reflect.DeepEqual(tc.target, tc.expected) {
+					t.Fatalf("Expected %+v, got %+v", tc.expected, tc.target)
+				}
+			})
+		}
+	})
+	t.Run("option", func(t *testing.T) {
+		n := NullUint16{}
+		if err := n.UnmarshalOption(8080); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if n != NewNullUint16(8080) {
+			t.Fatalf("Expected valid uint16 with value 8080, got %+v", n)
+		}
+		if err := n.UnmarshalOption(-1); err == nil {
+			t.Fatalf("Expected error, got %+v", n)
+		}
+		if err := n.UnmarshalOption(nil); err != nil || n.Valid {
+			t.Fatalf("Expected invalid uint16, got %+v (%v)", n, err)
+		}
+	})
+}
+
+func TestNullUintValue(t *testing.T) {
+	if got := NewNullUint(1).Value(); got != 1 {
+		t.Errorf("NullUint.Value() = %v, want %v", got, 1)
+	}
+	if got := NewNullUint8(8).Value(); got != 8 {
+		t.Errorf("NullUint8.Value() = %v, want %v", got, 8)
+	}
+	if got := NewNullUint16(16).Value(); got != 16 {
+		t.Errorf("NullUint16.Value() = %v, want %v", got, 16)
+	}
+	if got := NewNullUint32(32).Value(); got != 32 {
+		t.Errorf("NullUint32.Value() = %v, want %v", got, 32)
+	}
+	if got := NewNullUint64(64).Value(); got != 64 {
+		t.Errorf("NullUint64.Value() = %v, want %v", got, 64)
+	}
+}
+
+func TestNull(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		type Config struct {
+			Uint    Null[uint16]        `json:"uint"`
+			Timeout Null[time.Duration] `json:"timeout"`
+			Start   Null[time.Time]     `json:"start"`
+			Addr    Null[netip.Addr]    `json:"addr"`
+			Missing Null[string]        `json:"missing"`
+		}
+		config := &Config{}
+		data := []byte(`{"uint": 80, "timeout": "1m30s", "start": "2024-01-02T03:04:05Z", "addr": "10.0.0.1", "missing": null}`)
+		if err := json.Unmarshal(data, config); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := Config{
+			Uint:    NewNull[uint16](80),
+			Timeout: NewNull(90 * time.Second),
+			Start:   NewNull(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+			Addr:    NewNull(netip.MustParseAddr("10.0.0.1")),
+		}
+		if *config != expected {
+			t.Fatalf("Expected %+v, got %+v", expected, *config)
+		}
+
+		raw, err := json.Marshal(Config{Uint: NewNull[uint16](80)})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expectedRaw := `{"uint":80,"timeout":null,"start":null,"addr":null,"missing":null}`
+		if string(raw) != expectedRaw {
+			t.Fatalf("Expected data %v got %v", expectedRaw, string(raw))
+		}
+	})
+	t.Run("environment", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			value    string
+			target   interface{ UnmarshalEnvironmentValue([]byte) error }
+			expected any
+		}{
+			{name: "bool", value: "true", target: &Null[bool]{}, expected: &Null[bool]{V: true, Valid: true}},
+			{name: "int16", value: "-16", target: &Null[int16]{}, expected: &Null[int16]{V: -16, Valid: true}},
+			{name: "uint16", value: "8080", target: &Null[uint16]{}, expected: &Null[uint16]{V: 8080, Valid: true}},
+			{name: "complex128", value: "1+2i", target: &Null[complex128]{}, expected: &Null[complex128]{V: complex(1, 2), Valid: true}},
+			{name: "float32", value: "3.2", target: &Null[float32]{}, expected: &Null[float32]{V: 3.2, Valid: true}},
+			{name: "string", value: "value", target: &Null[string]{}, expected: &Null[string]{V: "value", Valid: true}},
+			{name: "duration", value: "5s", target: &Null[time.Duration]{}, expected: &Null[time.Duration]{V: 5 * time.Second, Valid: true}},
+			{name: "time", value: "2024-01-02T03:04:05Z", target: &Null[time.Time]{}, expected: &Null[time.Time]{V: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}},
+			{name: "text unmarshaler", value: "::1", target: &Null[netip.Addr]{}, expected: &Null[netip.Addr]{V: netip.IPv6Loopback(), Valid: true}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				if err := tc.target.UnmarshalEnvironmentValue([]byte(tc.value)); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if !reflect.DeepEqual(tc.target, tc.expected) {
+					t.Fatalf("Expected %+v, got %+v", tc.expected, tc.target)
+				}
+			})
+		}
+
+		n := Null[int8]{}
+		if err := n.UnmarshalEnvironmentValue([]byte("300")); err == nil {
+			t.Fatalf("Expected out of range error, got %v", err)
+		}
+		if n.Valid {
+			t.Fatalf("Expected invalid value after failed parse, got valid=%v", n.Valid)
+		}
+	})
+	t.Run("option", func(t *testing.T) {
+		n := Null[string]{}
+		value := "value"
+		if err := n.UnmarshalOption(&value); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if n != NewNull("value") {
+			t.Fatalf("Expected valid string with value 'value', got %+v", n)
+		}
+		if err := n.UnmarshalOption(1); err == nil {
+			t.Fatalf("Expected error, got %v", err)
+		}
+	})
+}
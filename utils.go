@@ -1,23 +1,50 @@
 package gottings
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
 )
 
-func LoadConfiguration(data []byte, v any) error {
-	var err error
+// LoadConfiguration fills v from the JSON document data and then from the
+// environment, as LoadEnv does. Default tags are applied before the JSON
+// document, so that both the document and the environment override them.
+// Required fields that no source set are reported in a *MissingError, and
+// values that cannot be stored are reported as *FieldError.
+func LoadConfiguration(data []byte, v any, opts ...LoadOption) error {
+	return loadConfiguration(data, v, opts, (*loadState).loadJSONDocument)
+}
+
+// documentDecoder decodes a whole configuration document into a struct.
+type documentDecoder func(st *loadState, elem reflect.Value, data []byte) error
+
+// loadConfiguration runs the pipeline shared by the LoadConfiguration
+// functions: defaults, then the document decoded by decode, then the
+// environment, then the required fields check. decode only returns an
+// error when the document as a whole cannot be read; field errors are
+// collected in the load state. Within a Loader only the document is
+// decoded.
+func loadConfiguration(data []byte, v any, opts []LoadOption, decode documentDecoder) error {
+	elem, err := structElem(v)
+	if err != nil {
+		return err
+	}
+	st := newLoadState(opts)
+	if !st.layered {
+		st.applyDefaults(elem, "")
+	}
 	if len(data) > 0 {
-		err = json.Unmarshal(data, v)
-		if err != nil {
+		if err := decode(st, elem, data); err != nil {
 			return err
 		}
 	}
-	err = LoadEnv(v)
-	if err != nil {
-		return err
+	if !st.layered {
+		st.loadEnv(elem, "", "")
+		st.checkRequired(elem)
 	}
-	return nil
+	return st.err()
 }
 
 func IsInteger(v any) bool {
@@ -71,6 +98,55 @@ func ToInt64(v any) (int64, error) {
 	}
 }
 
+// IsUnsigned reports whether v is an unsigned integer or a pointer to one.
+// IsInteger only reports signed integers.
+func IsUnsigned(v any) bool {
+	switch v.(type) {
+	case uint, uint8, uint16, uint32, uint64, uintptr, *uint, *uint8, *uint16, *uint32, *uint64, *uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// ToUint64 returns the value of the unsigned integer v, or of the unsigned
+// integer v points to.
+func ToUint64(v any) (uint64, error) {
+	if !IsUnsigned(v) {
+		return 0, errors.New("expected v to satisfy IsUnsigned(v) == true")
+	}
+
+	switch n := v.(type) {
+	case uint:
+		return uint64(n), nil
+	case uint8:
+		return uint64(n), nil
+	case uint16:
+		return uint64(n), nil
+	case uint32:
+		return uint64(n), nil
+	case uint64:
+		return n, nil
+	case uintptr:
+		return uint64(n), nil
+	case *uint:
+		return uint64(*n), nil
+	case *uint8:
+		return uint64(*n), nil
+	case *uint16:
+		return uint64(*n), nil
+	case *uint32:
+		return uint64(*n), nil
+	case *uint64:
+		return *n, nil
+	case *uintptr:
+		return uint64(*n), nil
+	default:
+		// This should never be reached due to the IsUnsigned check
+		return 0, errors.New("unexpected type encountered")
+	}
+}
+
 func ToFloat64(v any) (float64, error) {
 	isF := IsFloat(v)
 	if !isF {
@@ -91,3 +167,145 @@ func ToFloat64(v any) (float64, error) {
 		return 0., errors.New("unexpected type encountered")
 	}
 }
+
+// isNumberKind reports whether k is an integer, float or complex kind.
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return isComplexKind(k)
+}
+
+func isComplexKind(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
+
+// setNumber stores the number rv into targetValue, converting between
+// integer, unsigned, float and complex kinds. A value that does not fit
+// targetValue is reported as an error wrapping strconv.ErrRange, and a
+// conversion that would lose precision, such as 1.5 into an int, 1<<60 into
+// a float32 or 1+2i into a float64, is refused.
+func setNumber(targetValue, rv reflect.Value) error {
+	t := targetValue.Type()
+	if isComplexKind(rv.Kind()) && !isComplexKind(targetValue.Kind()) {
+		c := rv.Complex()
+		if imag(c) != 0 {
+			return errLossy(rv, t)
+		}
+		rv = reflect.ValueOf(real(c))
+	}
+	switch targetValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if rv.Uint() > math.MaxInt64 {
+				return errOutOfRange(rv, t)
+			}
+			n = int64(rv.Uint())
+		default:
+			f := rv.Float()
+			if f != math.Trunc(f) {
+				return errLossy(rv, t)
+			}
+			if f < math.MinInt64 || f >= math.MaxInt64 {
+				return errOutOfRange(rv, t)
+			}
+			n = int64(f)
+		}
+		if targetValue.OverflowInt(n) {
+			return errOutOfRange(rv, t)
+		}
+		targetValue.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.Int() < 0 {
+				return errOutOfRange(rv, t)
+			}
+			n = uint64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n = rv.Uint()
+		default:
+			f := rv.Float()
+			if f != math.Trunc(f) {
+				return errLossy(rv, t)
+			}
+			if f < 0 || f >= math.MaxUint64 {
+				return errOutOfRange(rv, t)
+			}
+			n = uint64(f)
+		}
+		if targetValue.OverflowUint(n) {
+			return errOutOfRange(rv, t)
+		}
+		targetValue.SetUint(n)
+	case reflect.Complex64, reflect.Complex128:
+		if isComplexKind(rv.Kind()) {
+			c := rv.Complex()
+			if targetValue.OverflowComplex(c) {
+				return errOutOfRange(rv, t)
+			}
+			targetValue.SetComplex(c)
+			return nil
+		}
+		// The real part of a complex64 is a float32.
+		f, err := toFloat(rv, t, t.Bits()/2)
+		if err != nil {
+			return err
+		}
+		targetValue.SetComplex(complex(f, 0))
+	default:
+		f, err := toFloat(rv, t, t.Bits())
+		if err != nil {
+			return err
+		}
+		targetValue.SetFloat(f)
+	}
+	return nil
+}
+
+// toFloat converts the integer or float rv to a float of the given size
+// for setNumber, which stores it into a value of type t.
+func toFloat(rv reflect.Value, t reflect.Type, bits int) (float64, error) {
+	var f float64
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		f = float64(n)
+		if f >= math.MaxInt64 || int64(f) != n {
+			return 0, errLossy(rv, t)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := rv.Uint()
+		f = float64(n)
+		if f >= math.MaxUint64 || uint64(f) != n {
+			return 0, errLossy(rv, t)
+		}
+	default:
+		f = rv.Float()
+	}
+	if bits == 32 {
+		if abs := math.Abs(f); abs > math.MaxFloat32 && !math.IsInf(abs, 1) {
+			return 0, errOutOfRange(rv, t)
+		}
+		if rv.Kind() != reflect.Float32 && rv.Kind() != reflect.Float64 && float64(float32(f)) != f {
+			return 0, errLossy(rv, t)
+		}
+	}
+	return f, nil
+}
+
+func errOutOfRange(rv reflect.Value, t reflect.Type) error {
+	return fmt.Errorf("cannot use %v as %s: %w", rv, t, strconv.ErrRange)
+}
+
+func errLossy(rv reflect.Value, t reflect.Type) error {
+	return fmt.Errorf("cannot use %v as %s without losing precision", rv, t)
+}
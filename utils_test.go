@@ -1,7 +1,12 @@
 package gottings
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"os"
+	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -44,6 +49,139 @@ func TestLoadConfiguration(t *testing.T) {
 			t.Errorf("Expected 127.0.0.1 got config.Host=%s\n", config.Host)
 		}
 	})
+	t.Run("unsigned and complex values", func(t *testing.T) {
+		type Config struct {
+			Port   uint16           `json:"port"`
+			Mask   NullUint32       `json:"mask"`
+			Size   *uint64          `json:"size"`
+			Phase  complex128       `json:"phase"`
+			Gain   *complex64       `json:"gain"`
+			Factor Null[complex128] `json:"factor"`
+		}
+		data := []byte(`{"port": 8080, "mask": 4294967295, "size": 18446744073709551615, "phase": "1+2i", "gain": 0.5, "factor": "-3i"}`)
+		config := Config{}
+		if err := LoadConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if config.Port != 8080 || config.Mask != NewNullUint32(math.MaxUint32) || config.Size == nil || *config.Size != math.MaxUint64 {
+			t.Errorf("unexpected unsigned values %+v", config)
+		}
+		if config.Phase != complex(1, 2) || config.Gain == nil || *config.Gain != 0.5 || config.Factor != NewNull(complex(0, -3)) {
+			t.Errorf("unexpected complex values %+v", config)
+		}
+
+		err := LoadConfiguration([]byte(`{"port": -1, "phase": "x"}`), &Config{})
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", err)
+		}
+	})
+	t.Run("self-referential struct", func(t *testing.T) {
+		type Node struct {
+			Name string `json:"name" env:"TEST_NAME" default:"node"`
+			Next *Node  `json:"next"`
+		}
+		node := Node{}
+		if err := LoadConfiguration([]byte(`{"next": {"name": "leaf"}}`), &node); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if node.Name != "node" || node.Next == nil || node.Next.Name != "leaf" || node.Next.Next != nil {
+			t.Errorf("unexpected result %+v", node)
+		}
+	})
+	t.Run("string tag option", func(t *testing.T) {
+		type Config struct {
+			Port  int      `json:"port,string"`
+			Ratio *float64 `json:"ratio,string"`
+			Debug bool     `json:",string"`
+		}
+		config := Config{}
+		if err := LoadConfiguration([]byte(`{"port": "8080", "ratio": "0.5", "Debug": "true"}`), &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.Port != 8080 || config.Ratio == nil || *config.Ratio != 0.5 || !config.Debug {
+			t.Errorf("unexpected result %+v", config)
+		}
+		if err := LoadConfiguration([]byte(`{"port": 8080}`), &Config{}); err == nil {
+			t.Errorf("expected an error for an unquoted value")
+		}
+	})
+	t.Run("keys differing in case", func(t *testing.T) {
+		type Config struct {
+			Port int `json:"port"`
+		}
+		for i := 0; i < 20; i++ {
+			config := Config{}
+			if err := LoadConfiguration([]byte(`{"port": 1, "PORT": 2, "Port": 3}`), &config); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			expected := Config{}
+			if err := json.Unmarshal([]byte(`{"port": 1, "PORT": 2, "Port": 3}`), &expected); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if config != expected {
+				t.Fatalf("expected %+v as encoding/json decodes it, got %+v", expected, config)
+			}
+		}
+	})
+	t.Run("embedded pointer to unexported struct", func(t *testing.T) {
+		type inner struct {
+			Host string `json:"host"`
+		}
+		type Config struct {
+			*inner
+			Port int `json:"port"`
+		}
+		config := Config{}
+		err := LoadConfiguration([]byte(`{"host": "x", "port": 1}`), &config)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Path != "inner" || fieldErr.Source != SourceJSON {
+			t.Fatalf("expected *FieldError for inner, got %v", err)
+		}
+		if config.Port != 1 || config.inner != nil {
+			t.Errorf("unexpected result %+v", config)
+		}
+		if err := LoadConfiguration([]byte(`{"port": 1}`), &Config{}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+	t.Run("embedded field dominance", func(t *testing.T) {
+		type Base struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+			Name string
+		}
+		type Other struct {
+			Name string
+		}
+		type Outer struct {
+			Base
+			Other
+			Host string `json:"host"`
+		}
+		config := Outer{}
+		data := []byte(`{"host": "outer", "port": 1, "name": "ambiguous"}`)
+		if err := LoadConfiguration(data, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Outer{Base: Base{Port: 1}, Host: "outer"}
+		if config != expected {
+			t.Errorf("expected %+v, got %+v", expected, config)
+		}
+	})
+	t.Run("document not an object", func(t *testing.T) {
+		for _, data := range []string{`[1, 2]`, `3`, `"host"`} {
+			err := LoadConfiguration([]byte(data), &Config{})
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Fatalf("expected *json.UnmarshalTypeError for %s, got %v", data, err)
+			}
+			var fieldErr *FieldError
+			if errors.As(err, &fieldErr) {
+				t.Errorf("expected a document error for %s, got %v", data, err)
+			}
+		}
+	})
 	t.Run("keys in json", func(t *testing.T) {
 		t.Setenv("TEST_PORT", "8080")
 		t.Setenv("TEST_HOST", "127.0.0.1")
@@ -156,6 +294,74 @@ func TestToInt64(t *testing.T) {
 	}
 }
 
+func TestIsUnsigned(t *testing.T) {
+	var u16 uint16 = uint16(5)
+	var i64 int64 = int64(5)
+	testCases := []struct {
+		input    any
+		expected bool
+	}{
+		{input: uint(5), expected: true},        // uint
+		{input: uint8(5), expected: true},       // uint8
+		{input: u16, expected: true},            // uint16
+		{input: &u16, expected: true},           // *uint16
+		{input: uint32(5), expected: true},      // uint32
+		{input: uint64(5), expected: true},      // uint64
+		{input: uintptr(5), expected: true},     // uintptr
+		{input: 5, expected: false},             // int
+		{input: i64, expected: false},           // int64
+		{input: &i64, expected: false},          // *int64
+		{input: 3.14, expected: false},          // float64
+		{input: "string", expected: false},      // string
+		{input: nil, expected: false},           // nil
+		{input: complex(1, 1), expected: false}, // complex
+	}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			result := IsUnsigned(tc.input)
+			if result != tc.expected {
+				t.Errorf("IsUnsigned(%v) = %v; expected %v", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestToUint64(t *testing.T) {
+	var u32 uint32 = uint32(5)
+	var i int = 5
+	testCases := []struct {
+		input    any
+		expected uint64
+		hasError bool
+	}{
+		{input: uint(5), expected: 5, hasError: false},
+		{input: uint8(5), expected: 5, hasError: false},
+		{input: uint16(5), expected: 5, hasError: false},
+		{input: u32, expected: 5, hasError: false},
+		{input: &u32, expected: 5, hasError: false},
+		{input: uint64(math.MaxUint64), expected: math.MaxUint64, hasError: false},
+		{input: uintptr(5), expected: 5, hasError: false},
+		{input: i, expected: 0, hasError: true},
+		{input: &i, expected: 0, hasError: true},
+		{input: 3.14, expected: 0, hasError: true},
+		{input: "string", expected: 0, hasError: true},
+		{input: nil, expected: 0, hasError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			result, err := ToUint64(tc.input)
+			if (err != nil) != tc.hasError {
+				t.Errorf("ToUint64(%v) error = %v, expected error = %v", tc.input, err, tc.hasError)
+			}
+			if result != tc.expected {
+				t.Errorf("ToUint64(%v) = %d; expected %d", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
 func TestToFloat64(t *testing.T) {
 	var i int = 5
 	var f64 float64 = float64(3.14)
@@ -185,3 +391,65 @@ func TestToFloat64(t *testing.T) {
 		})
 	}
 }
+
+func TestSetNumber(t *testing.T) {
+	int64Value := int64(42)
+	testCases := []struct {
+		name     string
+		target   any
+		value    any
+		expected any
+		errRange bool
+		hasError bool
+	}{
+		{name: "int64 into int8", target: new(int8), value: int64(100), expected: int8(100)},
+		{name: "int64 overflows int8", target: new(int8), value: int64(300), errRange: true},
+		{name: "negative overflows int8", target: new(int8), value: -129, errRange: true},
+		{name: "pointer to int64 into int16", target: new(int16), value: &int64Value, expected: int16(42)},
+		{name: "uint into int", target: new(int), value: uint(7), expected: 7},
+		{name: "uint64 overflows int64", target: new(int64), value: uint64(math.MaxUint64), errRange: true},
+		{name: "integral float into int32", target: new(int32), value: 12.0, expected: int32(12)},
+		{name: "fractional float into int", target: new(int), value: 1.5, hasError: true},
+		{name: "float overflows int64", target: new(int64), value: 1e19, errRange: true},
+		{name: "int into uint8", target: new(uint8), value: 255, expected: uint8(255)},
+		{name: "int overflows uint8", target: new(uint8), value: 256, errRange: true},
+		{name: "negative into uint", target: new(uint), value: -1, errRange: true},
+		{name: "float into uint16", target: new(uint16), value: float32(8), expected: uint16(8)},
+		{name: "negative float into uint", target: new(uint64), value: -1.0, errRange: true},
+		{name: "uint64 into uint32", target: new(uint32), value: uint64(1 << 32), errRange: true},
+		{name: "int into float64", target: new(float64), value: 3, expected: 3.0},
+		{name: "large int into float64", target: new(float64), value: int64(1<<53 + 1), hasError: true},
+		{name: "int into float32", target: new(float32), value: 1 << 24, expected: float32(1 << 24)},
+		{name: "large int into float32", target: new(float32), value: 1<<24 + 1, hasError: true},
+		{name: "uint into float64", target: new(float64), value: uint64(1 << 60), expected: float64(1 << 60)},
+		{name: "float64 into float32", target: new(float32), value: 0.5, expected: float32(0.5)},
+		{name: "float64 overflows float32", target: new(float32), value: 1e39, errRange: true},
+		{name: "int into complex128", target: new(complex128), value: 3, expected: complex(3, 0)},
+		{name: "large int into complex64", target: new(complex64), value: 1<<24 + 1, hasError: true},
+		{name: "complex128 into complex64", target: new(complex64), value: complex(1, 2), expected: complex64(complex(1, 2))},
+		{name: "complex128 overflows complex64", target: new(complex64), value: complex(1e39, 0), errRange: true},
+		{name: "real complex into int", target: new(int), value: complex(4, 0), expected: 4},
+		{name: "imaginary complex into float64", target: new(float64), value: complex(1, 2), hasError: true},
+		{name: "string into complex128", target: new(complex128), value: "1+2i", expected: complex(1, 2)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := reflect.ValueOf(tc.target).Elem()
+			err := setOption(target, tc.value)
+			switch {
+			case tc.errRange:
+				if !errors.Is(err, strconv.ErrRange) {
+					t.Fatalf("expected strconv.ErrRange, got %v", err)
+				}
+			case tc.hasError:
+				if err == nil {
+					t.Fatalf("expected error, got %v", target.Interface())
+				}
+			case err != nil:
+				t.Fatalf("expected no error, got %v", err)
+			case target.Interface() != tc.expected:
+				t.Errorf("expected %v (%T), got %v (%T)", tc.expected, tc.expected, target.Interface(), target.Interface())
+			}
+		})
+	}
+}
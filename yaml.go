@@ -0,0 +1,306 @@
+package gottings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// LoadYAMLConfiguration is like LoadConfiguration but reads a YAML
+// document. Keys are matched against the yaml tag of each field, then its
+// json tag, then its name, case-insensitively. A null value unsets Null*
+// fields and pointers as it does in JSON. Field errors carry the line and
+// column of the offending value.
+//
+//	type Config struct {
+//	     Host string           `yaml:"host" env:"APP_HOST"`
+//	     Port gottings.NullInt `json:"port" env:"APP_PORT"`
+//	}
+func LoadYAMLConfiguration(data []byte, v any, opts ...LoadOption) error {
+	return loadConfiguration(data, v, opts, (*loadState).loadYAMLDocument)
+}
+
+// loadYAMLDocument decodes the YAML document data into elem.
+func (st *loadState) loadYAMLDocument(elem reflect.Value, data []byte) error {
+	if reflect.PointerTo(elem.Type()).Implements(yamlUnmarshalerType) {
+		return yaml.Unmarshal(data, elem.Addr().Interface())
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := resolveYAMLAlias(doc.Content[0])
+	if isYAMLNull(root) {
+		return nil
+	}
+	st.loadYAML(elem, root, "", "")
+	return nil
+}
+
+// loadYAML decodes the mapping node into the struct elem. path is the field
+// path of elem and keyPath its YAML key path. It reports whether any field
+// was set.
+func (st *loadState) loadYAML(elem reflect.Value, node *yaml.Node, path, keyPath string) bool {
+	if node.Kind != yaml.MappingNode {
+		st.addError(yamlFieldError(path, keyPath, node, fmt.Errorf("cannot decode %s into %s", node.ShortTag(), elem.Type())))
+		return false
+	}
+	entries := yamlMappingEntries(node)
+	set := false
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		fieldValue := elem.Field(i)
+		name, opts := parseTag(field.Tag.Get("yaml"))
+		if name == "" && !opts.contains("inline") {
+			name, _ = parseTag(field.Tag.Get("json"))
+		}
+		if name == "-" {
+			continue
+		}
+		if (opts.contains("inline") || field.Anonymous && name == "") && isYAMLObject(field.Type) {
+			set = st.descendEmbedded(fieldValue, joinPath(path, field.Name), SourceYAML, keyPath, func(st *loadState, nested reflect.Value) bool {
+				return st.loadYAML(nested, node, path, keyPath)
+			}) || set
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		entry, ok := lookupYAMLKey(entries, name)
+		if !ok {
+			continue
+		}
+		fieldPath := joinPath(path, field.Name)
+		fieldKeyPath := joinPath(keyPath, entry.key)
+		if isYAMLObject(field.Type) && !isYAMLNull(entry.value) {
+			set = descend(fieldValue, func(nested reflect.Value) bool {
+				return st.loadYAML(nested, entry.value, fieldPath, fieldKeyPath)
+			}) || set
+			continue
+		}
+		if err := decodeYAMLValue(fieldValue, entry.value, fieldPath, fieldKeyPath); err != nil {
+			st.addFieldError(yamlFieldError(fieldPath, fieldKeyPath, entry.value, err))
+			continue
+		}
+		st.record(fieldValue, field, Origin{
+			Path:   fieldPath,
+			Source: SourceYAML,
+			Key:    fieldKeyPath,
+			File:   st.file,
+			Line:   entry.value.Line,
+			Column: entry.value.Column,
+		})
+		set = true
+	}
+	return set
+}
+
+// decodeYAMLValue stores node into target, which is not walked field by
+// field. path is the field path of target and keyPath its YAML key path,
+// which the errors of the structs found in sequences and mappings carry.
+func decodeYAMLValue(target reflect.Value, node *yaml.Node, path, keyPath string) error {
+	node = resolveYAMLAlias(node)
+	addr := target.Addr().Interface()
+	if isYAMLNull(node) {
+		if unmarshaler, ok := addr.(json.Unmarshaler); ok {
+			return unmarshaler.UnmarshalJSON([]byte("null"))
+		}
+		switch target.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			target.Set(reflect.Zero(target.Type()))
+		}
+		return nil
+	}
+	if target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return decodeYAMLValue(target.Elem(), node, path, keyPath)
+	}
+	if _, ok := addr.(yaml.Unmarshaler); ok {
+		return decodeYAMLNode(node, addr)
+	}
+	if target.Type() == timeType {
+		// yaml.v3 reads timestamps such as 2024-01-02, which
+		// time.Time.UnmarshalText rejects.
+		return decodeYAMLNode(node, addr)
+	}
+	if isNullType(target.Type()) && target.Field(0).Type() == timeType {
+		if err := decodeYAMLValue(target.Field(0), node, path, keyPath); err != nil {
+			return err
+		}
+		target.FieldByName("Valid").SetBool(true)
+		return nil
+	}
+	if hasUnmarshaler(target.Type()) || target.Type() == durationType || isComplexKind(target.Kind()) {
+		if node.Kind != yaml.ScalarNode {
+			return fmt.Errorf("cannot decode %s into %s", node.ShortTag(), target.Type())
+		}
+		return setScalar(target, node.Value)
+	}
+	if unmarshaler, ok := addr.(json.Unmarshaler); ok {
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return unmarshaler.UnmarshalJSON(data)
+	}
+
+	switch target.Kind() {
+	case reflect.Struct:
+		// Structs reached through slices and maps are decoded on their own.
+		st := newLoadState(nil)
+		st.loadYAML(target, node, path, keyPath)
+		return st.err()
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return fmt.Errorf("cannot decode %s into %s", node.ShortTag(), target.Type())
+		}
+		slice := reflect.MakeSlice(target.Type(), len(node.Content), len(node.Content))
+		for i, item := range node.Content {
+			if err := decodeYAMLValue(slice.Index(i), item, indexPath(path, i), indexPath(keyPath, i)); err != nil {
+				return elementError(i, err)
+			}
+		}
+		target.Set(slice)
+	case reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return fmt.Errorf("cannot decode %s into %s", node.ShortTag(), target.Type())
+		}
+		if len(node.Content) > target.Len() {
+			return fmt.Errorf("got %d elements for array of length %d", len(node.Content), target.Len())
+		}
+		array := reflect.New(target.Type()).Elem()
+		for i, item := range node.Content {
+			if err := decodeYAMLValue(array.Index(i), item, indexPath(path, i), indexPath(keyPath, i)); err != nil {
+				return elementError(i, err)
+			}
+		}
+		target.Set(array)
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("cannot decode %s into %s", node.ShortTag(), target.Type())
+		}
+		m := reflect.MakeMap(target.Type())
+		for _, entry := range yamlMappingEntries(node) {
+			key := reflect.New(target.Type().Key()).Elem()
+			if err := decodeYAMLValue(key, entry.keyNode, path, keyPath); err != nil {
+				return fmt.Errorf("key %q: %w", entry.key, err)
+			}
+			value := reflect.New(target.Type().Elem()).Elem()
+			if err := decodeYAMLValue(value, entry.value, keyedPath(path, entry.key), joinPath(keyPath, entry.key)); err != nil {
+				return valueError(entry.key, err)
+			}
+			m.SetMapIndex(key, value)
+		}
+		target.Set(m)
+	default:
+		return decodeYAMLNode(node, addr)
+	}
+	return nil
+}
+
+// decodeYAMLNode decodes node with yaml.v3, trimming the position from its
+// type errors since FieldError reports it already.
+func decodeYAMLNode(node *yaml.Node, addr any) error {
+	err := node.Decode(addr)
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) == 1 {
+		msg := typeErr.Errors[0]
+		if _, rest, ok := strings.Cut(msg, ": "); ok && strings.HasPrefix(msg, "line ") {
+			msg = rest
+		}
+		return errors.New(msg)
+	}
+	return err
+}
+
+// isYAMLObject reports whether t, or the type t points to, is a struct that
+// loadYAML walks rather than decoding it as a single value.
+func isYAMLObject(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	pt := reflect.PointerTo(t)
+	return isNestedStruct(t) && !pt.Implements(yamlUnmarshalerType) && !pt.Implements(jsonUnmarshalerType)
+}
+
+type yamlEntry struct {
+	key     string
+	keyNode *yaml.Node
+	value   *yaml.Node
+}
+
+// yamlMappingEntries returns the entries of a mapping node in document
+// order, with the entries of "<<" merge keys placed before the others so
+// that explicit keys win.
+func yamlMappingEntries(node *yaml.Node) []yamlEntry {
+	var merged, entries []yamlEntry
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, value := node.Content[i], resolveYAMLAlias(node.Content[i+1])
+		if keyNode.Tag == "!!merge" {
+			sources := []*yaml.Node{value}
+			if value.Kind == yaml.SequenceNode {
+				sources = value.Content
+			}
+			for _, source := range sources {
+				if source = resolveYAMLAlias(source); source.Kind == yaml.MappingNode {
+					merged = append(merged, yamlMappingEntries(source)...)
+				}
+			}
+			continue
+		}
+		entries = append(entries, yamlEntry{key: keyNode.Value, keyNode: keyNode, value: value})
+	}
+	return append(merged, entries...)
+}
+
+// lookupYAMLKey finds name in entries, preferring an exact match over a
+// case-insensitive one. Later entries win, as in encoding/json.
+func lookupYAMLKey(entries []yamlEntry, name string) (yamlEntry, bool) {
+	var fold yamlEntry
+	exact, found := false, false
+	for _, entry := range entries {
+		if entry.key == name {
+			fold, exact, found = entry, true, true
+		} else if !exact && strings.EqualFold(entry.key, name) {
+			fold, found = entry, true
+		}
+	}
+	return fold, found
+}
+
+func resolveYAMLAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func isYAMLNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+func yamlFieldError(path, keyPath string, node *yaml.Node, err error) *FieldError {
+	return &FieldError{Path: path, Source: SourceYAML, Key: keyPath, Line: node.Line, Column: node.Column, Err: err}
+}
@@ -0,0 +1,205 @@
+package gottings
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadYAMLConfiguration(t *testing.T) {
+	type Database struct {
+		Host string  `yaml:"host"`
+		Port NullInt `json:"port"`
+	}
+	type Replica struct {
+		Name   string `yaml:"name"`
+		Weight int    `yaml:"weight"`
+	}
+	type Config struct {
+		Host     string              `yaml:"host" json:"json_host" env:"TEST_HOST"`
+		Port     int                 `json:"port" env:"TEST_PORT"`
+		Timeout  Null[time.Duration] `yaml:"timeout"`
+		Name     NullString          `yaml:"name"`
+		Debug    *bool               `yaml:"debug"`
+		Tags     []string            `yaml:"tags"`
+		Labels   map[string]int      `yaml:"labels"`
+		Database *Database           `yaml:"database"`
+		Replicas []Replica           `yaml:"replicas"`
+		Skipped  string              `yaml:"-"`
+	}
+
+	t.Run("load from file", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "8080")
+		data, err := os.ReadFile("testdata/info.yaml")
+		if err != nil {
+			panic(err)
+		}
+		config := Config{}
+		if err := LoadYAMLConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if config.Port != 8080 {
+			t.Errorf("Expected 8080 got config.Port=%d\n", config.Port)
+		}
+		if config.Host != "127.0.0.1" {
+			t.Errorf("Expected 127.0.0.1 got config.Host=%s\n", config.Host)
+		}
+	})
+	t.Run("all supported values", func(t *testing.T) {
+		data := []byte(`
+host: example.com
+PORT: 8080
+timeout: 1m
+name: ~
+debug: false
+tags: [a, b]
+labels:
+  x: 1
+  y: 2
+database:
+  host: db
+  port: 5432
+replicas:
+  - name: r1
+    weight: 1
+  - name: r2
+Skipped: value
+`)
+		config := Config{Name: NewNullString("previous")}
+		if err := LoadYAMLConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		debug := false
+		expected := Config{
+			Host:     "example.com",
+			Port:     8080,
+			Timeout:  NewNull(time.Minute),
+			Name:     NullString{String: "previous"},
+			Debug:    &debug,
+			Tags:     []string{"a", "b"},
+			Labels:   map[string]int{"x": 1, "y": 2},
+			Database: &Database{Host: "db", Port: NewNullInt(5432)},
+			Replicas: []Replica{{Name: "r1", Weight: 1}, {Name: "r2"}},
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v\n", config, expected)
+		}
+	})
+	t.Run("timestamps", func(t *testing.T) {
+		type Config struct {
+			Day     time.Time       `yaml:"day"`
+			Start   *time.Time      `yaml:"start"`
+			Release Null[time.Time] `yaml:"release"`
+		}
+		data := []byte(`
+day: 2024-01-02
+start: 2024-01-02T15:04:05Z
+release: !!timestamp 2024-03-04
+`)
+		config := Config{}
+		if err := LoadYAMLConfiguration(data, &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+		expected := Config{
+			Day:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Start:   &start,
+			Release: NewNull(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)),
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v", config, expected)
+		}
+	})
+	t.Run("anchors and merge keys", func(t *testing.T) {
+		data := []byte(`
+base: &base
+  host: base
+  port: 1
+database:
+  <<: *base
+  port: 2
+tags: &tags [x]
+`)
+		config := Config{}
+		if err := LoadYAMLConfiguration(data, &config); err != nil {
+			t.Fatalf("expected error to be nil, got %s", err)
+		}
+		if !reflect.DeepEqual(config.Database, &Database{Host: "base", Port: NewNullInt(2)}) {
+			t.Errorf("unexpected database %+v", config.Database)
+		}
+	})
+	t.Run("field errors carry line and column", func(t *testing.T) {
+		data := []byte("host: example.com\nport: http\ndatabase:\n  port: [1]\nreplicas:\n  - name: r1\n  - weight: x\n")
+		err := LoadYAMLConfiguration(data, &Config{})
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Fatalf("expected 3 errors, got %v", err)
+		}
+		expected := []FieldError{
+			{Path: "Port", Source: SourceYAML, Key: "port", Line: 2, Column: 7},
+			{Path: "Database.Port", Source: SourceYAML, Key: "database.port", Line: 4, Column: 9},
+			{Path: "Replicas[1].Weight", Source: SourceYAML, Key: "replicas[1].weight", Line: 7, Column: 13},
+		}
+		for i, err := range errs {
+			fieldErr := err.(*FieldError)
+			got := FieldError{Path: fieldErr.Path, Source: fieldErr.Source, Key: fieldErr.Key, Line: fieldErr.Line, Column: fieldErr.Column}
+			if got != expected[i] {
+				t.Errorf("expected %+v, got %+v", expected[i], got)
+			}
+		}
+	})
+	t.Run("unsigned and complex values", func(t *testing.T) {
+		type Config struct {
+			Port  uint16     `yaml:"port"`
+			Mask  NullUint8  `yaml:"mask"`
+			Sizes []uint64   `yaml:"sizes"`
+			Phase complex128 `yaml:"phase"`
+			Gain  complex64  `yaml:"gain"`
+		}
+		config := Config{}
+		if err := LoadYAMLConfiguration([]byte("port: 8080\nmask: 255\nsizes: [1, 2]\nphase: 1+2i\ngain: 0.5\n"), &config); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := Config{
+			Port:  8080,
+			Mask:  NewNullUint8(255),
+			Sizes: []uint64{1, 2},
+			Phase: complex(1, 2),
+			Gain:  0.5,
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("result %+v does not match expected %+v", config, expected)
+		}
+		if err := LoadYAMLConfiguration([]byte("port: -1\n"), &Config{}); err == nil {
+			t.Fatalf("expected error, got %v", err)
+		}
+	})
+	t.Run("embedded pointer to unexported struct", func(t *testing.T) {
+		type inner struct {
+			Host string `yaml:"host"`
+		}
+		type Config struct {
+			*inner
+			Port int `yaml:"port"`
+		}
+		config := Config{}
+		err := LoadYAMLConfiguration([]byte("host: x\nport: 1\n"), &config)
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Path != "inner" || fieldErr.Source != SourceYAML {
+			t.Fatalf("expected *FieldError for inner, got %v", err)
+		}
+		if config.Port != 1 || config.inner != nil {
+			t.Errorf("unexpected result %+v", config)
+		}
+		if err := LoadYAMLConfiguration([]byte("port: 1\n"), &Config{}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		if err := LoadYAMLConfiguration([]byte("host: [unclosed"), &Config{}); err == nil {
+			t.Fatalf("expected error, got %v", err)
+		}
+	})
+}